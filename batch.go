@@ -0,0 +1,97 @@
+package fft
+
+import "fmt"
+
+// FFTBatch implements the fast Fourier transform on each of the len(x)/n
+// contiguous length n vectors in x, such as the rows of a row-major matrix.
+// This is done in-place (modifying the input array).
+// multithread tells the algorithm to spread the vectors across the shared
+// worker pool (see SetParallelism), which can slow things down for small len(x).
+// Requires O(1) additional memory.
+// n must be a perfect power of 2, and len(x) must be a multiple of n,
+// otherwise this will return an error.
+func FFTBatch(x []complex128, n int, multithread bool) error {
+	if err := checkBatch("FFTBatch", x, n); err != nil {
+		return err
+	}
+	batch(x, n, multithread, fft)
+	return nil
+}
+
+// IFFTBatch implements the inverse fast Fourier transform on each of the len(x)/n
+// contiguous length n vectors in x, such as the rows of a row-major matrix,
+// scaling each by 1/n, so that IFFTBatch(FFTBatch(x)) == x.
+// This is done in-place (modifying the input array).
+// multithread tells the algorithm to spread the vectors across the shared
+// worker pool (see SetParallelism), which can slow things down for small len(x).
+// Requires O(1) additional memory.
+// n must be a perfect power of 2, and len(x) must be a multiple of n,
+// otherwise this will return an error.
+func IFFTBatch(x []complex128, n int, multithread bool) error {
+	if err := checkBatch("IFFTBatch", x, n); err != nil {
+		return err
+	}
+	batch(x, n, multithread, ifft)
+	return nil
+}
+
+// FFTEach implements the fast Fourier transform on each frame in frames,
+// which may be of different lengths, returning an error for each frame,
+// aligned with frames: nil if it was transformed, or the error FFT would
+// return for it, in which case it is left unaltered. One invalid frame does not
+// stop the others from being transformed.
+// This is done in-place (modifying the input arrays).
+// The frames are spread across the shared worker pool when the total length N
+// of the valid frames is enough work, N*log2(N), to reach ParallelThreshold,
+// and transformed on the calling goroutine otherwise. The result is the same either way.
+// Requires O(len(frames)) additional memory.
+func FFTEach(frames [][]complex128) []error {
+	errs := make([]error, len(frames))
+	valid := make([][]complex128, 0, len(frames))
+	total := 0
+	for i, x := range frames {
+		if errs[i] = checkLength(fmt.Sprintf("FFTEach frame %d", i), len(x)); errs[i] == nil {
+			valid = append(valid, x)
+			total += len(x)
+		}
+	}
+	if !useParallel(total) || len(valid) < 2 {
+		for _, x := range valid {
+			fft(x)
+		}
+		return errs
+	}
+	workers := min(Parallelism(), len(valid))
+	parallel(workers, func(j int) {
+		for _, x := range valid[j*len(valid)/workers : (j+1)*len(valid)/workers] {
+			fft(x)
+		}
+	})
+	return errs
+}
+
+// checkBatch checks that n is a valid power of 2 dividing len(x)
+func checkBatch(Context string, x []complex128, n int) error {
+	if err := checkLength(Context+" vector length", n); err != nil {
+		return err
+	}
+	return checkZero(Context+" remainder", len(x)%n)
+}
+
+// batch applies f to each contiguous length n vector in x,
+// optionally spread across the worker pool.
+func batch(x []complex128, n int, multithread bool, f func([]complex128)) {
+	M := len(x) / n
+	if !multithread {
+		for i := 0; i < M; i++ {
+			f(x[i*n : (i+1)*n])
+		}
+		return
+	}
+	workers := Parallelism()
+	parallel(workers, func(j int) {
+		for i := j * M / workers; i < (j+1)*M/workers; i++ {
+			f(x[i*n : (i+1)*n])
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package fft
+
+import (
+	"fmt"
+	"math/cmplx"
+	"runtime"
+	"testing"
+)
+
+func TestFFTBatch(t *testing.T) {
+	// Test FFTBatch of non-powers of 2 or uneven batches returns InputSizeError
+	checkIsInputSizeError(t, "FFTBatch(complexRand(34), 17, false)", FFTBatch(complexRand(34), 17, false))
+	checkIsInputSizeError(t, "FFTBatch(complexRand(12), 8, false)", FFTBatch(complexRand(12), 8, false))
+	// Test FFTBatch(x) == slowFFT of each vector, for several batch sizes
+	for _, multithread := range []bool{false, true} {
+		for n := 1; n < (1 << 8); n <<= 1 {
+			for _, m := range []int{0, 1, 3, 17} {
+				x := complexRand(n * m)
+				y := copyVector(x)
+				if err := FFTBatch(y, n, multithread); err != nil {
+					t.Errorf("FFTBatch error: %v", err)
+				}
+				for i := 0; i < m; i++ {
+					r := slowFFT(x[i*n : (i+1)*n])
+					for k := 0; k < n; k++ {
+						if e := cmplx.Abs(r[k] - y[i*n+k]); e > 1e-9 {
+							t.Errorf("slowFFT and FFTBatch differ: n=%d m=%d vector=%d k=%d diff=%v", n, m, i, k, e)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIFFTBatch(t *testing.T) {
+	// Test IFFTBatch of non-powers of 2 or uneven batches returns InputSizeError
+	checkIsInputSizeError(t, "IFFTBatch(complexRand(34), 17, false)", IFFTBatch(complexRand(34), 17, false))
+	checkIsInputSizeError(t, "IFFTBatch(complexRand(12), 8, false)", IFFTBatch(complexRand(12), 8, false))
+	// Test IFFTBatch(x) == IFFT of each vector, and IFFTBatch(FFTBatch(x)) == x
+	for _, multithread := range []bool{false, true} {
+		for n := 1; n < (1 << 8); n <<= 1 {
+			for _, m := range []int{0, 1, 3, 17} {
+				x := complexRand(n * m)
+				y := copyVector(x)
+				if err := IFFTBatch(y, n, multithread); err != nil {
+					t.Errorf("IFFTBatch error: %v", err)
+				}
+				for i := 0; i < m; i++ {
+					r := copyVector(x[i*n : (i+1)*n])
+					IFFT(r)
+					for k := 0; k < n; k++ {
+						if e := cmplx.Abs(r[k] - y[i*n+k]); e > 1e-9 {
+							t.Errorf("IFFT and IFFTBatch differ: n=%d m=%d vector=%d k=%d diff=%v", n, m, i, k, e)
+						}
+					}
+				}
+				y = copyVector(x)
+				FFTBatch(y, n, multithread)
+				IFFTBatch(y, n, multithread)
+				for i := range x {
+					if e := cmplx.Abs(x[i] - y[i]); e > 1e-9 {
+						t.Errorf("IFFTBatch(FFTBatch(x)) differs: n=%d m=%d i=%d diff=%v", n, m, i, e)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkFFTBatch(b *testing.B) {
+	for _, bm := range benchmarks {
+		procs := runtime.GOMAXPROCS(0)
+		x := complexRand(bm.size * procs)
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(bm.size * procs * 16))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				FFTBatch(x, bm.size, true)
+			}
+		})
+	}
+}
+
+func TestFFTEach(t *testing.T) {
+	defer SetParallelThreshold(0)
+	defer SetParallelism(0)
+	SetParallelism(4)
+	// Test both sides of the threshold, with invalid frames among valid frames of mixed lengths
+	for _, threshold := range []int{1, 1 << 30} {
+		SetParallelThreshold(threshold)
+		var frames, inputs [][]complex128
+		for _, n := range []int{8, 12, 1, 0, 256, 3, 64, 64, 2} {
+			x := complexRand(n)
+			inputs = append(inputs, x)
+			frames = append(frames, copyVector(x))
+		}
+		errs := FFTEach(frames)
+		if len(errs) != len(frames) {
+			t.Fatalf("FFTEach errors length, got: %d, expected: %d", len(errs), len(frames))
+		}
+		for i, x := range inputs {
+			if !IsPow2(len(x)) {
+				checkIsInputSizeError(t, fmt.Sprintf("FFTEach frame %d of length %d", i, len(x)), errs[i])
+				for k := range x {
+					if frames[i][k] != x[k] {
+						t.Errorf("FFTEach altered invalid frame %d", i)
+						break
+					}
+				}
+				continue
+			}
+			if errs[i] != nil {
+				t.Errorf("FFTEach frame %d error: %v", i, errs[i])
+			}
+			r := slowFFT(x)
+			for k := range r {
+				if e := cmplx.Abs(r[k] - frames[i][k]); e > 1e-9 {
+					t.Errorf("slowFFT and FFTEach differ: threshold=%d frame=%d k=%d diff=%v", threshold, i, k, e)
+				}
+			}
+		}
+	}
+	if errs := FFTEach(nil); len(errs) != 0 {
+		t.Errorf("FFTEach(nil), got: %v, expected: []", errs)
+	}
+}
@@ -0,0 +1,121 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// minLogMagnitude is the smallest magnitude taken the log of, to avoid log(0)
+const minLogMagnitude = 1e-300
+
+// DefaultCepstrumEpsilon is the smallest magnitude that Cepstrum and
+// ComplexCepstrum take the log of; smaller magnitudes are clamped to it.
+// Use CepstrumEpsilon and ComplexCepstrumEpsilon to choose another.
+const DefaultCepstrumEpsilon = 1e-12
+
+// MinPhase computes the minimum-phase spectrum with the magnitude response mag,
+// the full length N magnitude of a spectrum in FFT order.
+// The real cepstrum of mag (the IFFT of log(mag)) is folded onto the causal
+// quefrencies, doubling the positive ones and zeroing the negative ones,
+// and transformed back, giving exp(FFT(folded cepstrum)).
+// mag should be conjugate-symmetric, as for a real filter. Zero magnitudes are
+// clamped to a tiny positive value before taking the log.
+// This does not alter mag, and returns a new array.
+// len(mag) must be a perfect power of 2, otherwise this will return an error.
+func MinPhase(mag []float64) ([]complex128, error) {
+	if err := checkLength("MinPhase Input", len(mag)); err != nil {
+		return nil, err
+	}
+	N := len(mag)
+	c := make([]complex128, N)
+	for i, v := range mag {
+		c[i] = complex(math.Log(math.Max(v, minLogMagnitude)), 0)
+	}
+	ifft(c)
+	// Fold the real cepstrum onto the causal quefrencies
+	c[0] = complex(real(c[0]), 0)
+	for n := 1; n < (N+1)/2; n++ {
+		c[n] = complex(2*real(c[n]), 0)
+		c[N-n] = 0
+	}
+	if N > 1 {
+		c[N/2] = complex(real(c[N/2]), 0)
+	}
+	fft(c)
+	for i, v := range c {
+		c[i] = cmplx.Exp(v)
+	}
+	return c, nil
+}
+
+// Cepstrum computes the real cepstrum of x, IFFT(log(|FFT(x)|)), returning a new array.
+// An echo of delay d samples in x appears as a peak at quefrency (index) d.
+// Magnitudes below DefaultCepstrumEpsilon are clamped to it before taking the log.
+// This does not alter x.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func Cepstrum(x []float64) ([]float64, error) {
+	return CepstrumEpsilon(x, DefaultCepstrumEpsilon)
+}
+
+// CepstrumEpsilon computes the real cepstrum of x like Cepstrum, but clamps
+// magnitudes below epsilon to it before taking the log.
+// epsilon is raised to at least 1e-300, so that 0 magnitudes never give -Inf.
+// This does not alter x.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func CepstrumEpsilon(x []float64, epsilon float64) ([]float64, error) {
+	if err := checkLength("Cepstrum Input", len(x)); err != nil {
+		return nil, err
+	}
+	epsilon = math.Max(epsilon, minLogMagnitude)
+	c := Float64ToComplex128Array(x)
+	fft(c)
+	for i, v := range c {
+		c[i] = complex(math.Log(math.Max(cmplx.Abs(v), epsilon)), 0)
+	}
+	ifft(c)
+	return Complex128ToFloat64Array(c), nil
+}
+
+// ComplexCepstrum computes the complex cepstrum of x, IFFT(log(FFT(x))), keeping
+// the phase, and returning a new array and the delay removed from it.
+// The phase of the non-negative frequency bins is unwrapped, and the linear
+// phase of a circular delay of delay samples is removed, as by MATLAB's cceps,
+// so that the unwrapped phase is 0 at Nyquist. The phase of the negative frequency bins
+// mirrors it, keeping the cepstrum real.
+// Magnitudes below DefaultCepstrumEpsilon are clamped to it before taking the log.
+// This does not alter x.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func ComplexCepstrum(x []float64) (c []float64, delay int, err error) {
+	return ComplexCepstrumEpsilon(x, DefaultCepstrumEpsilon)
+}
+
+// ComplexCepstrumEpsilon computes the complex cepstrum of x like ComplexCepstrum,
+// but clamps magnitudes below epsilon to it before taking the log.
+// epsilon is raised to at least 1e-300, so that 0 magnitudes never give -Inf.
+// This does not alter x.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func ComplexCepstrumEpsilon(x []float64, epsilon float64) (c []float64, delay int, err error) {
+	if err := checkLength("ComplexCepstrum Input", len(x)); err != nil {
+		return nil, 0, err
+	}
+	epsilon = math.Max(epsilon, minLogMagnitude)
+	N := len(x)
+	X := Float64ToComplex128Array(x)
+	fft(X)
+	phase := unwrapPhase(Phase(X[:N/2+1]), math.Pi)
+	// A delay of d samples has a phase of -Pi*d at Nyquist
+	delay = -int(math.Round(phase[N/2] / math.Pi))
+	for k := 0; k <= N/2; k++ {
+		p := phase[k]
+		if N > 1 {
+			p += math.Pi * float64(delay) * float64(k) / float64(N/2)
+		}
+		v := complex(math.Log(math.Max(cmplx.Abs(X[k]), epsilon)), p)
+		X[k] = v
+		if k != 0 && k != N-k {
+			X[N-k] = cmplx.Conj(v)
+		}
+	}
+	ifft(X)
+	return Complex128ToFloat64Array(X), delay, nil
+}
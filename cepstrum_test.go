@@ -0,0 +1,157 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestMinPhase(t *testing.T) {
+	// Test MinPhase of non-powers of 2 returns InputSizeError
+	_, err := MinPhase(floatRand(17))
+	checkIsInputSizeError(t, "MinPhase(floatRand(17))", err)
+	// Test the magnitude of MinPhase(mag) == mag, for the magnitude of a random real filter
+	for N := 2; N < (1 << 11); N <<= 1 {
+		h := Float64ToComplex128Array(floatRand(8))
+		h = ZeroPad(h, max(N, 8))[:N]
+		FFT(h)
+		mag := Magnitude(h)
+		y, err := MinPhase(mag)
+		if err != nil {
+			t.Errorf("MinPhase error: %v", err)
+		}
+		for k := range mag {
+			if e := math.Abs(cmplx.Abs(y[k]) - mag[k]); e > 1e-6*math.Max(1, mag[k]) {
+				t.Errorf("MinPhase magnitude differs: N=%d k=%d got=%v expected=%v diff=%v", N, k, cmplx.Abs(y[k]), mag[k], e)
+			}
+		}
+	}
+	// Test MinPhase recovers an already minimum-phase filter, 1 + 0.5z^-1
+	N := 256
+	h := make([]complex128, N)
+	h[0], h[1] = 1, 0.5
+	FFT(h)
+	y, err := MinPhase(Magnitude(h))
+	if err != nil {
+		t.Fatalf("MinPhase error: %v", err)
+	}
+	for k := range h {
+		if e := cmplx.Abs(h[k] - y[k]); e > 1e-9 {
+			t.Errorf("MinPhase of minimum-phase filter differs: k=%d got=%v expected=%v diff=%v", k, y[k], h[k], e)
+		}
+	}
+	// Test 0 magnitudes are clamped to 1e-300, independent of DefaultCepstrumEpsilon
+	y, _ = MinPhase(make([]float64, 8))
+	for k, v := range y {
+		if e := math.Abs(cmplx.Abs(v)/1e-300 - 1); e > 1e-9 {
+			t.Errorf("MinPhase of 0 magnitudes differs: k=%d got=%v expected=1e-300", k, cmplx.Abs(v))
+		}
+	}
+}
+
+// echoSignal returns a random decaying burst of length 32 at the start of n samples,
+// plus its echo delayed by delay samples and scaled by gain
+func echoSignal(n, delay int, gain float64) []float64 {
+	x := make([]float64, n)
+	for i, v := range floatRand(32) {
+		s := (v - 0.5) * math.Exp(-float64(i)/8)
+		x[i] += s
+		x[i+delay] += gain * s
+	}
+	return x
+}
+
+// peakQuefrency returns the index of the largest |c[i]| for i in [lo, hi)
+func peakQuefrency(c []float64, lo, hi int) int {
+	peak := lo
+	for i := lo; i < hi; i++ {
+		if math.Abs(c[i]) > math.Abs(c[peak]) {
+			peak = i
+		}
+	}
+	return peak
+}
+
+func TestCepstrum(t *testing.T) {
+	// Test non-powers of 2 return InputSizeError
+	_, err := Cepstrum(floatRand(17))
+	checkIsInputSizeError(t, "Cepstrum(floatRand(17))", err)
+	_, _, err = ComplexCepstrum(floatRand(17))
+	checkIsInputSizeError(t, "ComplexCepstrum(floatRand(17))", err)
+	// Test Cepstrum of a unit impulse is all 0s
+	c, err := Cepstrum([]float64{1, 0, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		t.Errorf("Cepstrum error: %v", err)
+	}
+	for i, v := range c {
+		if math.Abs(v) > 1e-12 {
+			t.Errorf("Cepstrum of an impulse differs: c[%d]=%v expected 0", i, v)
+		}
+	}
+	// Test an echo shows a clear peak at the echo delay quefrency
+	N := 1024
+	for _, delay := range []int{100, 200, 333} {
+		x := echoSignal(N, delay, 0.5)
+		c, err := Cepstrum(x)
+		if err != nil {
+			t.Errorf("Cepstrum error: %v", err)
+		}
+		if got := peakQuefrency(c, 40, N/2); got != delay {
+			t.Errorf("Cepstrum peak of an echo, got: %d, expected: %d", got, delay)
+		}
+		cc, _, err := ComplexCepstrum(x)
+		if err != nil {
+			t.Errorf("ComplexCepstrum error: %v", err)
+		}
+		if got := peakQuefrency(cc, 40, N/2); got != delay {
+			t.Errorf("ComplexCepstrum peak of an echo, got: %d, expected: %d", got, delay)
+		}
+	}
+	// Test the epsilon variants: the default matches, and a larger epsilon raises the
+	// floor of the log magnitude, which for a spectrum of 0s is log(epsilon) at quefrency 0
+	x0 := echoSignal(64, 10, 0.5)
+	c1, _ := Cepstrum(x0)
+	c2, err := CepstrumEpsilon(x0, DefaultCepstrumEpsilon)
+	if err != nil {
+		t.Errorf("CepstrumEpsilon error: %v", err)
+	}
+	cc1, _, _ := ComplexCepstrum(x0)
+	cc2, _, err := ComplexCepstrumEpsilon(x0, DefaultCepstrumEpsilon)
+	if err != nil {
+		t.Errorf("ComplexCepstrumEpsilon error: %v", err)
+	}
+	for i := range c1 {
+		if c1[i] != c2[i] || cc1[i] != cc2[i] {
+			t.Errorf("Cepstrum and the epsilon variants with the default differ at %d", i)
+		}
+	}
+	for _, eps := range []float64{1e-3, 1e-12, 0, -1} {
+		expect := math.Log(math.Max(eps, 1e-300))
+		c, _ := CepstrumEpsilon(make([]float64, 8), eps)
+		cc, _, _ := ComplexCepstrumEpsilon(make([]float64, 8), eps)
+		if math.Abs(c[0]-expect) > 1e-9 || math.Abs(cc[0]-expect) > 1e-9 {
+			t.Errorf("CepstrumEpsilon of 0s with epsilon=%v, got: %v and %v, expected: %v", eps, c[0], cc[0], expect)
+		}
+	}
+	_, err = CepstrumEpsilon(floatRand(17), 1e-6)
+	checkIsInputSizeError(t, "CepstrumEpsilon(floatRand(17), 1e-6)", err)
+	// Test ComplexCepstrum of a delayed impulse removes the delay, leaving all 0s
+	x := make([]float64, 16)
+	x[3] = 2
+	cc, delay, err := ComplexCepstrum(x)
+	if err != nil {
+		t.Errorf("ComplexCepstrum error: %v", err)
+	}
+	if delay != 3 {
+		t.Errorf("ComplexCepstrum delay of a delayed impulse, got: %d, expected: 3", delay)
+	}
+	for i, v := range cc {
+		expect := 0.0
+		if i == 0 {
+			expect = math.Log(2)
+		}
+		if e := math.Abs(v - expect); e > 1e-9 {
+			t.Errorf("ComplexCepstrum of a delayed impulse differs: c[%d]=%v expected %v diff=%v", i, v, expect, e)
+		}
+	}
+}
@@ -1,16 +1,23 @@
 package fft
 
 import (
-	"runtime"
-	"sync"
+	"context"
+	"fmt"
+	"math/cmplx"
 )
 
 // Convolve computes the discrete convolution of x and y using FFT.
 // Pads x and y to the next power of 2 from len(x)+len(y)-1
+// If both x and y are purely real (every imaginary part is exactly 0), this uses
+// ConvolveReal, doing about half the work, and the result is purely real too.
 func Convolve(x, y []complex128) ([]complex128, error) {
 	if len(x) == 0 && len(y) == 0 {
 		return nil, nil
 	}
+	if isReal(x) && isReal(y) {
+		r, err := ConvolveReal(Complex128ToFloat64Array(x), Complex128ToFloat64Array(y))
+		return Float64ToComplex128Array(r), err
+	}
 	n := len(x) + len(y) - 1
 	N := NextPow2(n)
 	x = ZeroPad(x, N)
@@ -19,6 +26,107 @@ func Convolve(x, y []complex128) ([]complex128, error) {
 	return x[:n], err
 }
 
+// ConvMode selects the portion of the full convolution returned by ConvolveMode,
+// matching the mode parameter of numpy.convolve.
+type ConvMode int
+
+const (
+	// ConvFull returns the full convolution, of length len(x)+len(y)-1, as Convolve does.
+	ConvFull ConvMode = iota
+	// ConvSame returns the central portion of the full convolution, of length
+	// max(len(x), len(y)).
+	ConvSame
+	// ConvValid returns only the portion where x and y overlap completely, of
+	// length max(len(x), len(y))-min(len(x), len(y))+1.
+	ConvValid
+)
+
+// ConvolveMode computes the discrete convolution of x and y using FFT, as
+// Convolve does, returning the portion of it selected by mode.
+// The ConvSame portion starts at index (min(len(x), len(y))-1)/2 of the full
+// convolution, the same as numpy.convolve.
+// This does not alter x or y.
+// len(x) and len(y) must be positive, otherwise this will return an error.
+func ConvolveMode(x, y []complex128, mode ConvMode) ([]complex128, error) {
+	if err := checkPositive("ConvolveMode x length", len(x)); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("ConvolveMode y length", len(y)); err != nil {
+		return nil, err
+	}
+	r, err := Convolve(x, y)
+	if err != nil {
+		return nil, err
+	}
+	short, long := min(len(x), len(y)), max(len(x), len(y))
+	switch mode {
+	case ConvSame:
+		start := (short - 1) / 2
+		return r[start : start+long], nil
+	case ConvValid:
+		return r[short-1 : long], nil
+	}
+	return r, nil
+}
+
+// ConvolveReal computes the discrete convolution of real x and y using FFT,
+// returning a new array of length len(x)+len(y)-1.
+// x and y are packed into the real and imaginary parts of a single complex FFT,
+// and the real result is recovered with a complex IFFT of half the length,
+// doing about half the work of Convolve.
+// Pads to the next power of 2 from len(x)+len(y)-1. This does not alter x or y.
+func ConvolveReal(x, y []float64) ([]float64, error) {
+	if len(x) == 0 && len(y) == 0 {
+		return nil, nil
+	}
+	n := len(x) + len(y) - 1
+	if len(x) == 0 || len(y) == 0 {
+		return make([]float64, max(n, 0)), nil
+	}
+	N := NextPow2(n)
+	if N == 1 {
+		return []float64{x[0] * y[0]}, nil
+	}
+	z := make([]complex128, N)
+	for i, v := range x {
+		z[i] = complex(v, 0)
+	}
+	for i, v := range y {
+		z[i] += complex(0, v)
+	}
+	fft(z)
+	// With Z = X + i*Y, X[k]*Y[k] = (Z[k]^2 - conj(Z[N-k])^2) / 4i
+	half := make([]complex128, N/2+1)
+	for k := range half {
+		a, b := z[k], cmplx.Conj(z[(N-k)%N])
+		half[k] = (a*a - b*b) / complex(0, 4)
+	}
+	return irfftHalf(half, N)[:n], nil
+}
+
+// ConvolveAuto computes the discrete convolution of x and y like Convolve, but
+// runs the two forward transforms in parallel on the shared worker pool when
+// the padded length N is enough work, N*log2(N), to reach ParallelThreshold.
+// Below the threshold, or if x and y are both purely real, this is the same as Convolve.
+// This does not alter x or y.
+func ConvolveAuto(x, y []complex128) ([]complex128, error) {
+	n := len(x) + len(y) - 1
+	N := NextPow2(n)
+	if n <= 0 || !useParallel(N) || (isReal(x) && isReal(y)) {
+		return Convolve(x, y)
+	}
+	x = ZeroPad(x, N)
+	y = ZeroPad(y, N)
+	parallel(2, func(j int) {
+		fft([][]complex128{x, y}[j])
+	})
+	for i := range x {
+		x[i] *= y[i]
+	}
+	ifft(x)
+	return x[:n], nil
+}
+
 // FastConvolve computes the discrete convolution of x and y using FFT
 // and stores the result in x, while erasing y (setting it to 0s).
 // Since this does no allocations, x and y are assumed to already be 0-padded
@@ -37,6 +145,109 @@ func FastConvolve(x, y []complex128) error {
 	return nil
 }
 
+// CircularConvolve computes the circular (cyclic) convolution of x and y using FFT,
+// r[k] = sum(x[j] * y[(k-j) mod N]), returning a new array of length N = len(x).
+// Unlike Convolve and FastConvolve, which compute the linear convolution by
+// 0-padding so that nothing wraps around, here the end of the result wraps
+// around onto its start.
+// This does not alter x or y.
+// len(x) and len(y) must be equal, and a perfect power of 2, otherwise this will return an error.
+func CircularConvolve(x, y []complex128) ([]complex128, error) {
+	if err := checkZero("difference in CircularConvolve input vectors length", len(x)-len(y)); err != nil {
+		return nil, err
+	}
+	if err := checkLength("CircularConvolve input vector length", len(x)); err != nil {
+		return nil, err
+	}
+	r := ZeroPad(x, len(x))
+	convolve(r, ZeroPad(y, len(y)))
+	return r, nil
+}
+
+// KernelConvolver computes the discrete convolution of many signals against
+// a fixed kernel, transforming the kernel only once.
+// A KernelConvolver is safe for concurrent use.
+type KernelConvolver struct {
+	kernel []complex128 // FFT of the 0-padded kernel
+	length int          // length of the kernel
+	n      int          // maximum signal length
+}
+
+// NewKernelConvolver creates a KernelConvolver for convolving signals of length
+// at most n against kernel. The FFT length is the next power of 2 from n+len(kernel)-1.
+// kernel must be non-empty and n must be positive, otherwise this will return an error.
+func NewKernelConvolver(kernel []complex128, n int) (*KernelConvolver, error) {
+	if err := checkPositive("KernelConvolver kernel length", len(kernel)); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("KernelConvolver signal length", n); err != nil {
+		return nil, err
+	}
+	k := ZeroPad(kernel, NextPow2(n+len(kernel)-1))
+	fft(k)
+	return &KernelConvolver{kernel: k, length: len(kernel), n: n}, nil
+}
+
+// Convolve computes the discrete convolution of x and the kernel,
+// returning a new array of length len(x)+len(kernel)-1.
+// This does not alter x. Returns nil if x is empty.
+// len(x) must be at most the n given to NewKernelConvolver, otherwise this will return an error.
+func (c *KernelConvolver) Convolve(x []complex128) ([]complex128, error) {
+	if len(x) > c.n {
+		return nil, &InputSizeError{Context: "KernelConvolver input length", Requirement: fmt.Sprintf("at most %d", c.n), Size: len(x), Err: ErrOutOfRange}
+	}
+	if len(x) == 0 {
+		return nil, nil
+	}
+	y := ZeroPad(x, len(c.kernel))
+	fft(y)
+	for i := range y {
+		y[i] *= c.kernel[i]
+	}
+	ifft(y)
+	return y[:len(x)+c.length-1], nil
+}
+
+// Deconvolve recovers x from y = Convolve(x, h) given the impulse response h,
+// returning x of length len(y)-len(h)+1.
+// Pads y and h to the next power of 2 from len(y), so that the circular
+// deconvolution done by the FFT is equivalent to the linear one. This only holds
+// if y is the full linear convolution, and not a truncated or circular one.
+// Each bin is divided as Y*conj(H)/(|H|^2+epsilon), where epsilon regularizes
+// bins where H is near zero, which would otherwise amplify noise without bound.
+// Use epsilon = 0 for exact spectral division. Bins where |H|^2+epsilon is 0
+// are set to 0, as nothing of Y survives there to recover.
+// This means kernels with a spectral zero on the FFT grid, such as any even-length
+// moving average, which is 0 at the Nyquist bin, can't be recovered exactly.
+// len(h) must be positive and no greater than len(y), and epsilon must be at least 0,
+// otherwise this will return an error.
+func Deconvolve(y, h []complex128, epsilon float64) ([]complex128, error) {
+	if err := checkPositive("Deconvolve impulse response length", len(h)); err != nil {
+		return nil, err
+	}
+	if err := checkNonNegative("Deconvolve epsilon", epsilon); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("Deconvolve output length", len(y)-len(h)+1); err != nil {
+		return nil, err
+	}
+	N := NextPow2(len(y))
+	Y := ZeroPad(y, N)
+	H := ZeroPad(h, N)
+	fft(Y)
+	fft(H)
+	for i := 0; i < N; i++ {
+		a := real(H[i])*real(H[i]) + imag(H[i])*imag(H[i]) + epsilon
+		if a == 0 {
+			Y[i] = 0
+			continue
+		}
+		Y[i] *= cmplx.Conj(H[i]) / complex(a, 0)
+	}
+	ifft(Y)
+	return Y[:len(y)-len(h)+1], nil
+}
+
 // MultiConvolve computes the discrete convolution of many arrays using a
 // hierarchical FFT algorithm that successfully builds up larger convolutions.
 // This requires allocating up to 4*N extra memory for appropriate 0-padding
@@ -123,10 +334,82 @@ func multiConvolveSingleLevel(arrays [][]complex128, returnLength int) ([]comple
 // Additionally, the number of arrays must be a power of 2
 // X is the concatenated array of arrays, of length N (n*m)
 // n is the length of the 0-padded arrays.
-// multithread tells the algorithm to use goroutines,
-// which can slow things down for small N.
+// multithread tells the algorithm to spread each level across the shared
+// worker pool (see SetParallelism), which can slow things down for small N.
+// The result is bit-for-bit identical whether or not multithread is set, and
+// whatever the number of CPUs, since each pairwise convolution is computed the
+// same way regardless of which goroutine runs it.
 // Takes O(N*log(N)^2) run time and O(1) additional space.
 func FastMultiConvolve(X []complex128, n int, multithread bool) error {
+	return FastMultiConvolveContext(context.Background(), X, n, multithread)
+}
+
+// PackForFastMultiConvolve lays out X as the input FastMultiConvolve expects,
+// returning the concatenated array flat and the padded length n of each array.
+// Each array is 0-padded to n, the next power of 2 from the sum of the lengths
+// of the longest two, and arrays of a single 1 are appended up to the next
+// power of 2 number of arrays, which leaves the convolution unchanged.
+// After FastMultiConvolve(flat, n, multithread), the convolution of X is in
+// flat[:sum(len(x)-1 for x in X)+1].
+// This does not alter X. Returns nil and 0 if X is empty.
+func PackForFastMultiConvolve(X ...[]complex128) ([]complex128, int) {
+	if len(X) == 0 {
+		return nil, 0
+	}
+	l1, l2 := 0, 0
+	for _, x := range X {
+		if len(x) > l1 {
+			l1, l2 = len(x), l1
+		} else if len(x) > l2 {
+			l2 = len(x)
+		}
+	}
+	n := NextPow2(l1 + l2)
+	m := NextPow2(len(X))
+	flat := make([]complex128, n*m)
+	for i, x := range X {
+		copy(flat[n*i:], x)
+	}
+	for i := len(X); i < m; i++ {
+		flat[n*i] = 1.0
+	}
+	return flat, n
+}
+
+// MultiConvolveAuto is FastMultiConvolve, but chooses whether to multithread
+// itself, using the worker pool when the total length N = len(X) is enough work,
+// N*log2(N), to reach ParallelThreshold, and the calling goroutine otherwise.
+// The result is the same either way.
+func MultiConvolveAuto(X []complex128, n int) error {
+	return FastMultiConvolve(X, n, useParallel(len(X)))
+}
+
+// FastMultiConvolveContext is FastMultiConvolve, but stops early and returns
+// ctx.Err() if ctx is cancelled. ctx is checked between each doubling level,
+// and between each convolution in the multithreaded path.
+// On cancellation the contents of X are undefined.
+func FastMultiConvolveContext(ctx context.Context, X []complex128, n int, multithread bool) error {
+	workers := 1
+	if multithread {
+		workers = Parallelism()
+	}
+	return fastMultiConvolve(ctx, X, n, workers)
+}
+
+// FastMultiConvolveN is the multithreaded FastMultiConvolve, but splits each
+// level into at most workers tasks, rather than Parallelism(), run on the shared
+// worker pool (see SetParallelism).
+// If workers <= 0, Parallelism() is used.
+func FastMultiConvolveN(X []complex128, n, workers int) error {
+	if workers <= 0 {
+		workers = Parallelism()
+	}
+	return fastMultiConvolve(context.Background(), X, n, workers)
+}
+
+// fastMultiConvolve does the actual work for FastMultiConvolve, spreading each
+// level into workers tasks on the worker pool, or running on the calling goroutine if workers == 1.
+func fastMultiConvolve(ctx context.Context, X []complex128, n, workers int) error {
 	if err := checkLength("Convolve single input array", n); err != nil {
 		return err
 	}
@@ -138,20 +421,22 @@ func FastMultiConvolve(X []complex128, n int, multithread bool) error {
 		return err
 	}
 	for ; n != N; n <<= 1 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		n2 := n << 1
-		if multithread {
-			var wg sync.WaitGroup
-			NumCPU := runtime.NumCPU()
-			for j := 0; j < NumCPU; j++ {
-				wg.Add(1)
-				go func(s, e int) {
-					defer wg.Done()
-					for i := s; i < e; i += n2 {
-						convolve(X[i:i+n], X[i+n:i+n2])
+		if workers > 1 {
+			parallel(workers, func(j int) {
+				for i := n2 * ((j * N / n2) / workers); i < n2*(((j+1)*N/n2)/workers); i += n2 {
+					if ctx.Err() != nil {
+						return
 					}
-				}(n2*((j*N/n2)/NumCPU), n2*(((j+1)*N/n2)/NumCPU))
+					convolve(X[i:i+n], X[i+n:i+n2])
+				}
+			})
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			wg.Wait()
 		} else {
 			for i := 0; i < N; i += n2 {
 				convolve(X[i:i+n], X[i+n:i+n2])
@@ -162,6 +447,9 @@ func FastMultiConvolve(X []complex128, n int, multithread bool) error {
 }
 
 // convolve does the actual work of convolutions.
+// This uses fft and ifft rather than skipping the permutations with fftNoPermute
+// and ifftFromBitReversed, whose plain radix-2 stages are slower than the
+// permutations they save.
 func convolve(x, y []complex128) {
 	fft(x)
 	fft(y)
@@ -1,9 +1,13 @@
 package fft
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
 	"math/cmplx"
 	"math/rand"
+	"runtime"
 	"testing"
 )
 
@@ -42,6 +46,193 @@ func TestConvolve(t *testing.T) {
 	}
 }
 
+func TestConvolveRealInput(t *testing.T) {
+	// Test Convolve of purely real inputs matches slowConvolve, with exactly zero imaginary parts
+	for _, s := range [][2]int{{1, 1}, {1, 7}, {5, 3}, {33, 64}, {100, 1}} {
+		x := Float64ToComplex128Array(floatRand(s[0]))
+		y := Float64ToComplex128Array(floatRand(s[1]))
+		r1 := slowConvolve(x, y)
+		r2, err := Convolve(x, y)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(r1) != len(r2) {
+			t.Errorf("slowConvolve and Convolve of real inputs differ in length: len(r1)=%d, len(r2)=%d", len(r1), len(r2))
+			continue
+		}
+		for k := range r1 {
+			if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9 {
+				t.Errorf("slowConvolve and Convolve of real inputs differ: r1[%d]=%v, r2[%d]=%v, diff=%v", k, r1[k], k, r2[k], e)
+			}
+			if imag(r2[k]) != 0 {
+				t.Errorf("Convolve of real inputs has an imaginary part: r2[%d]=%v", k, r2[k])
+			}
+		}
+	}
+	// Test a single complex entry still takes the complex path
+	x := Float64ToComplex128Array(floatRand(8))
+	x[3] += 1i
+	y := Float64ToComplex128Array(floatRand(5))
+	r1 := slowConvolve(x, y)
+	r2, _ := Convolve(x, y)
+	for k := range r1 {
+		if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9 {
+			t.Errorf("slowConvolve and Convolve of mixed inputs differ: r1[%d]=%v, r2[%d]=%v, diff=%v", k, r1[k], k, r2[k], e)
+		}
+	}
+}
+
+func TestConvolveMode(t *testing.T) {
+	// Test empty inputs return InputSizeError
+	_, err := ConvolveMode(nil, complexRand(3), ConvSame)
+	checkIsInputSizeError(t, "ConvolveMode(nil, complexRand(3), ConvSame)", err)
+	_, err = ConvolveMode(complexRand(3), nil, ConvFull)
+	checkIsInputSizeError(t, "ConvolveMode(complexRand(3), nil, ConvFull)", err)
+	// Test the numpy.convolve documentation examples
+	for _, c := range []struct {
+		mode   ConvMode
+		expect []complex128
+	}{
+		{ConvFull, []complex128{0, 1, 2.5, 4, 1.5}},
+		{ConvSame, []complex128{1, 2.5, 4}},
+		{ConvValid, []complex128{2.5}},
+	} {
+		r, err := ConvolveMode([]complex128{1, 2, 3}, []complex128{0, 1, 0.5}, c.mode)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(r) != len(c.expect) {
+			t.Errorf("ConvolveMode(mode=%d) length, got: %d, expected: %d", c.mode, len(r), len(c.expect))
+			continue
+		}
+		for k := range r {
+			if e := cmplx.Abs(r[k] - c.expect[k]); e > 1e-9 {
+				t.Errorf("ConvolveMode(mode=%d) differs: r[%d]=%v, expected=%v, diff=%v", c.mode, k, r[k], c.expect[k], e)
+			}
+		}
+	}
+	// Test each mode is the expected slice of slowConvolve, with either argument longer
+	for i := 1; i < 20; i++ {
+		x := complexRand(i)
+		for j := 1; j < 20; j++ {
+			y := complexRand(j)
+			full := slowConvolve(x, y)
+			short, long := min(i, j), max(i, j)
+			for mode, expect := range map[ConvMode][]complex128{
+				ConvFull:  full,
+				ConvSame:  full[(short-1)/2 : (short-1)/2+long],
+				ConvValid: full[short-1 : long],
+			} {
+				r, err := ConvolveMode(x, y, mode)
+				if err != nil {
+					t.Error(err)
+				}
+				if len(r) != len(expect) {
+					t.Errorf("ConvolveMode(mode=%d) length differs: len(x)=%d, len(y)=%d, got: %d, expected: %d", mode, i, j, len(r), len(expect))
+					continue
+				}
+				for k := range r {
+					if e := cmplx.Abs(r[k] - expect[k]); e > 1e-9 {
+						t.Errorf("slowConvolve and ConvolveMode(mode=%d) differ: len(x)=%d, len(y)=%d, r[%d]=%v, expected=%v, diff=%v", mode, i, j, k, r[k], expect[k], e)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConvolveAuto(t *testing.T) {
+	defer SetParallelThreshold(0)
+	defer SetParallelism(0)
+	SetParallelism(4)
+	// Test both sides of the threshold match slowConvolve
+	for _, threshold := range []int{1, 1 << 30} {
+		SetParallelThreshold(threshold)
+		for _, s := range [][2]int{{0, 0}, {0, 3}, {1, 1}, {5, 3}, {33, 64}} {
+			x, y := complexRand(s[0]), complexRand(s[1])
+			r1 := slowConvolve(x, y)
+			r2, err := ConvolveAuto(x, y)
+			if err != nil {
+				t.Error(err)
+			}
+			if len(r1) != len(r2) {
+				t.Errorf("slowConvolve and ConvolveAuto differ in length: threshold=%d len(r1)=%d, len(r2)=%d", threshold, len(r1), len(r2))
+				continue
+			}
+			for k := range r1 {
+				if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9 {
+					t.Errorf("slowConvolve and ConvolveAuto differ: threshold=%d r1[%d]=%v, r2[%d]=%v, diff=%v", threshold, k, r1[k], k, r2[k], e)
+				}
+			}
+		}
+		// Test MultiConvolveAuto matches FastMultiConvolve exactly
+		X := complexRand(16 * 8)
+		for i := range X {
+			if i%16 >= 8 {
+				X[i] = 0
+			}
+		}
+		Y := copyVector(X)
+		FastMultiConvolve(Y, 16, false)
+		if err := MultiConvolveAuto(X, 16); err != nil {
+			t.Error(err)
+		}
+		for i := range X {
+			if X[i] != Y[i] {
+				t.Errorf("MultiConvolveAuto and FastMultiConvolve differ: threshold=%d i=%d %v %v", threshold, i, X[i], Y[i])
+			}
+		}
+	}
+}
+
+func TestConvolveReal(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		x := floatRand(i)
+		for j := 0; j < 64; j++ {
+			y := floatRand(j)
+			r1 := slowConvolve(Float64ToComplex128Array(x), Float64ToComplex128Array(y))
+			r2, err := ConvolveReal(x, y)
+			if err != nil {
+				t.Error(err)
+			}
+			if len(r1) != len(r2) {
+				t.Errorf("slowConvolve and ConvolveReal differ in length: len(r1)=%d, len(r2)=%d", len(r1), len(r2))
+				continue
+			}
+			for k := range r1 {
+				if e := math.Abs(real(r1[k]) - r2[k]); e > 1e-9 {
+					t.Errorf("slowConvolve and ConvolveReal differ: r1[%d]=%v, r2[%d]=%v, diff=%v", k, r1[k], k, r2[k], e)
+				}
+			}
+		}
+	}
+}
+
+func TestCircularConvolve(t *testing.T) {
+	// Test mismatched and non-power of 2 lengths return InputSizeError
+	_, err := CircularConvolve(complexRand(8), complexRand(4))
+	checkIsInputSizeError(t, "CircularConvolve(complexRand(8), complexRand(4))", err)
+	_, err = CircularConvolve(complexRand(12), complexRand(12))
+	checkIsInputSizeError(t, "CircularConvolve(complexRand(12), complexRand(12))", err)
+	// Test CircularConvolve(x, y) == slowConvolve(x, y) wrapped around to length N
+	for N := 1; N < (1 << 9); N <<= 1 {
+		x, y := complexRand(N), complexRand(N)
+		r1 := make([]complex128, N)
+		for i, v := range slowConvolve(x, y) {
+			r1[i%N] += v
+		}
+		r2, err := CircularConvolve(x, y)
+		if err != nil {
+			t.Errorf("CircularConvolve error: %v", err)
+		}
+		for i := range r1 {
+			if e := cmplx.Abs(r1[i] - r2[i]); e > 1e-9 {
+				t.Errorf("wrapped slowConvolve and CircularConvolve differ: N=%d r1[%d]=%v r2[%d]=%v diff=%v", N, i, r1[i], i, r2[i], e)
+			}
+		}
+	}
+}
+
 func TestFastConvolve(t *testing.T) {
 	// Test FastConvolve of zero inputs returns nil
 	x := complexRand(0)
@@ -53,8 +244,17 @@ func TestFastConvolve(t *testing.T) {
 	// Test FastConvolve of non-powers of 2 returns InputSizeError
 	err = FastConvolve(complexRand(17), complexRand(17))
 	checkIsInputSizeError(t, "FastConvolve(complexRand(17), complexRand(17))", err)
-	err = FastConvolve(complexRand(4), complexRand(8))
-	checkIsInputSizeError(t, "FastConvolve(complexRand(4), complexRand(8))", err)
+	if !errors.Is(err, ErrNotPow2) {
+		t.Errorf("FastConvolve(complexRand(17), complexRand(17)) returned %v, expected ErrNotPow2", err)
+	}
+	// Test FastConvolve of differing lengths returns InputSizeError, checked before the power of 2
+	for _, n := range [][2]int{{4, 8}, {3, 5}, {16, 0}} {
+		err = FastConvolve(complexRand(n[0]), complexRand(n[1]))
+		checkIsInputSizeError(t, fmt.Sprintf("FastConvolve(complexRand(%d), complexRand(%d))", n[0], n[1]), err)
+		if !errors.Is(err, ErrLengthMismatch) {
+			t.Errorf("FastConvolve(complexRand(%d), complexRand(%d)) returned %v, expected ErrLengthMismatch", n[0], n[1], err)
+		}
+	}
 	// Test FastConvolve(x, y) == slowConvolve(x, y)
 	for i := 1; i < 128; i++ {
 		N := NextPow2(2 * i)
@@ -85,6 +285,105 @@ func TestFastConvolve(t *testing.T) {
 	}
 }
 
+func TestKernelConvolver(t *testing.T) {
+	// Test NewKernelConvolver of an empty kernel or non-positive length returns InputSizeError
+	_, err := NewKernelConvolver(nil, 8)
+	checkIsInputSizeError(t, "NewKernelConvolver(nil, 8)", err)
+	_, err = NewKernelConvolver(complexRand(4), 0)
+	checkIsInputSizeError(t, "NewKernelConvolver(complexRand(4), 0)", err)
+	// Test KernelConvolver.Convolve(x) == slowConvolve(x, kernel) for many signals of length up to n
+	for j := 1; j < 32; j++ {
+		kernel := complexRand(j)
+		n := rand.Intn(64) + 1
+		c, err := NewKernelConvolver(kernel, n)
+		if err != nil {
+			t.Fatalf("NewKernelConvolver error: %v", err)
+		}
+		_, err = c.Convolve(complexRand(n + 1))
+		checkIsInputSizeError(t, "KernelConvolver.Convolve(complexRand(n+1))", err)
+		for i := 0; i <= n; i++ {
+			x := complexRand(i)
+			r1 := slowConvolve(x, kernel)
+			r2, err := c.Convolve(x)
+			if err != nil {
+				t.Error(err)
+			}
+			if i == 0 {
+				if r2 != nil {
+					t.Errorf("KernelConvolver.Convolve(nil), got: %v, expected: nil", r2)
+				}
+				continue
+			}
+			if len(r1) != len(r2) {
+				t.Errorf("slowConvolve and KernelConvolver differ in length: len(r1)=%d, len(r2)=%d", len(r1), len(r2))
+				continue
+			}
+			for k := range r1 {
+				if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9 {
+					t.Errorf("slowConvolve and KernelConvolver differ: r1[%d]=%v, r2[%d]=%v, diff=%v", k, r1[k], k, r2[k], e)
+				}
+			}
+		}
+	}
+}
+
+func TestDeconvolve(t *testing.T) {
+	// Test Deconvolve of an empty or too long impulse response returns InputSizeError
+	_, err := Deconvolve(complexRand(8), nil, 0)
+	checkIsInputSizeError(t, "Deconvolve(complexRand(8), nil, 0)", err)
+	_, err = Deconvolve(complexRand(8), complexRand(9), 0)
+	checkIsInputSizeError(t, "Deconvolve(complexRand(8), complexRand(9), 0)", err)
+	// Test Deconvolve with a negative or NaN epsilon returns a ParameterError
+	for _, epsilon := range []float64{-1e-12, math.NaN()} {
+		_, err = Deconvolve(complexRand(8), complexRand(2), epsilon)
+		var e *ParameterError
+		if !errors.As(err, &e) || !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Deconvolve(complexRand(8), complexRand(2), %v), got: %v, expected: *ParameterError", epsilon, err)
+		}
+	}
+	// Test Deconvolve by a kernel with a spectral zero stays finite, rather than dividing by 0
+	y, err := Convolve([]complex128{1, 2, 3}, []complex128{1, 1})
+	if err != nil {
+		t.Error(err)
+	}
+	r, err := Deconvolve(y, []complex128{1, 1}, 0)
+	if err != nil {
+		t.Error(err)
+	}
+	if i := firstInvalid(r); i >= 0 {
+		t.Errorf("Deconvolve(Convolve([1, 2, 3], [1, 1]), [1, 1], 0) has non-finite r[%d]=%v", i, r[i])
+	}
+	// Test Deconvolve(Convolve(x, h), h) == x
+	for i := 1; i < 64; i++ {
+		for j := 1; j < 8; j++ {
+			x := complexRand(i)
+			// Keep h well conditioned with a dominant first tap, so H has no zeros
+			h := complexRand(j)
+			for k := range h {
+				h[k] *= 0.05
+			}
+			h[0] += 1
+			y, err := Convolve(x, h)
+			if err != nil {
+				t.Error(err)
+			}
+			r, err := Deconvolve(y, h, 1e-12)
+			if err != nil {
+				t.Error(err)
+			}
+			if len(r) != len(x) {
+				t.Errorf("Deconvolve(Convolve(x, h), h) length, got: %d, expected: %d", len(r), len(x))
+				continue
+			}
+			for k := range x {
+				if e := cmplx.Abs(x[k] - r[k]); e > 1e-9 {
+					t.Errorf("Deconvolve(Convolve(x, h), h) differs: x[%d]=%v, r[%d]=%v, diff=%v", k, x[k], k, r[k], e)
+				}
+			}
+		}
+	}
+}
+
 func slowMultiConvolve(X [][]complex128) []complex128 {
 	m := []complex128{1.0}
 	for _, x := range X {
@@ -195,6 +494,131 @@ func TestFastMultiConvolve(t *testing.T) {
 	}
 }
 
+func TestPackForFastMultiConvolve(t *testing.T) {
+	// Test packing no arrays
+	if flat, n := PackForFastMultiConvolve(); flat != nil || n != 0 {
+		t.Errorf("PackForFastMultiConvolve(), got: %v, %d, expected: nil, 0", flat, n)
+	}
+	// Test the layout of a small example
+	flat, n := PackForFastMultiConvolve([]complex128{1, 2}, []complex128{3}, []complex128{4, 5, 6})
+	expect := []complex128{1, 2, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 4, 5, 6, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}
+	if n != 8 || len(flat) != len(expect) {
+		t.Fatalf("PackForFastMultiConvolve layout, got: n=%d len=%d, expected: n=8 len=%d", n, len(flat), len(expect))
+	}
+	for i := range flat {
+		if flat[i] != expect[i] {
+			t.Errorf("PackForFastMultiConvolve layout, got: %v, expected: %v", flat, expect)
+			break
+		}
+	}
+	// Test FastMultiConvolve(PackForFastMultiConvolve(X)) == slowMultiConvolve(X) for mixed lengths
+	for i := 1; i < 12; i++ {
+		X := make([][]complex128, i)
+		length := 1
+		for k := range X {
+			X[k] = complexRand(1 + rand.Intn(9))
+			length += len(X[k]) - 1
+		}
+		r1 := slowMultiConvolve(X)
+		flat, n := PackForFastMultiConvolve(X...)
+		if err := FastMultiConvolve(flat, n, false); err != nil {
+			t.Error(err)
+			continue
+		}
+		r2 := flat[:length]
+		if len(r1) != len(r2) {
+			t.Errorf("slowMultiConvolve and packed FastMultiConvolve differ in length: len(r1)=%d, len(r2)=%d", len(r1), len(r2))
+			continue
+		}
+		for k := range r1 {
+			if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9*math.Pow(10, float64(i)) {
+				t.Errorf("slowMultiConvolve and packed FastMultiConvolve differ: r1[%d]=%v, r2[%d]=%v, diff=%v, i=%d", k, r1[k], k, r2[k], e, i)
+			}
+		}
+	}
+}
+
+func TestFastMultiConvolveContext(t *testing.T) {
+	// Test FastMultiConvolveContext with a cancelled context returns ctx.Err()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, multithread := range []bool{false, true} {
+		X := complexRand(1 << 12)
+		err := FastMultiConvolveContext(ctx, X, 8, multithread)
+		if err != context.Canceled {
+			t.Errorf("FastMultiConvolveContext(cancelled, multithread=%t), got: %v, expected: %v", multithread, err, context.Canceled)
+		}
+	}
+	// Test FastMultiConvolveContext with a live context == FastMultiConvolve
+	for _, multithread := range []bool{false, true} {
+		X1 := make([]complex128, 16*8)
+		for k := 0; k < 16; k++ {
+			copy(X1[8*k:], complexRand(4))
+		}
+		X2 := copyVector(X1)
+		if err := FastMultiConvolve(X1, 8, multithread); err != nil {
+			t.Error(err)
+		}
+		if err := FastMultiConvolveContext(context.Background(), X2, 8, multithread); err != nil {
+			t.Error(err)
+		}
+		for k := range X1 {
+			if X1[k] != X2[k] {
+				t.Errorf("FastMultiConvolve and FastMultiConvolveContext differ: X1[%d]=%v, X2[%d]=%v", k, X1[k], k, X2[k])
+			}
+		}
+	}
+}
+
+func TestFastMultiConvolveN(t *testing.T) {
+	checkIsInputSizeError(t, "FastMultiConvolveN(make([]complex128, 12), 4, 2)", FastMultiConvolveN(make([]complex128, 12), 4, 2))
+	// Test FastMultiConvolveN == FastMultiConvolve for any number of workers
+	for _, workers := range []int{-1, 0, 1, 2, 3, 7, 64} {
+		X1 := make([]complex128, 32*8)
+		for k := 0; k < 32; k++ {
+			copy(X1[8*k:], complexRand(4))
+		}
+		X2 := copyVector(X1)
+		if err := FastMultiConvolve(X1, 8, false); err != nil {
+			t.Error(err)
+		}
+		if err := FastMultiConvolveN(X2, 8, workers); err != nil {
+			t.Error(err)
+		}
+		for k := range X1 {
+			if X1[k] != X2[k] {
+				t.Errorf("FastMultiConvolve and FastMultiConvolveN differ: workers=%d X1[%d]=%v, X2[%d]=%v", workers, k, X1[k], k, X2[k])
+			}
+		}
+	}
+}
+
+func TestFastMultiConvolveDeterministic(t *testing.T) {
+	// Test the output is bit-for-bit identical for any number of workers
+	for _, n := range []int{2, 16, 256} {
+		X := make([]complex128, 64*n)
+		for k := 0; k < 64; k++ {
+			copy(X[n*k:], complexRand(n/2))
+		}
+		expect := copyVector(X)
+		if err := FastMultiConvolve(expect, n, false); err != nil {
+			t.Error(err)
+		}
+		for workers := 2; workers < 10; workers++ {
+			got := copyVector(X)
+			if err := FastMultiConvolveN(got, n, workers); err != nil {
+				t.Error(err)
+			}
+			for k := range expect {
+				if math.Float64bits(real(expect[k])) != math.Float64bits(real(got[k])) || math.Float64bits(imag(expect[k])) != math.Float64bits(imag(got[k])) {
+					t.Errorf("FastMultiConvolveN not bit-identical: n=%d workers=%d got[%d]=%v, expected=%v", n, workers, k, got[k], expect[k])
+					break
+				}
+			}
+		}
+	}
+}
+
 func BenchmarkConvolve(b *testing.B) {
 	for _, bm := range benchmarks {
 		x := complexRand(bm.size)
@@ -210,6 +634,21 @@ func BenchmarkConvolve(b *testing.B) {
 	}
 }
 
+func BenchmarkConvolveReal(b *testing.B) {
+	for _, bm := range benchmarks {
+		x := floatRand(bm.size)
+		y := floatRand(bm.size)
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(bm.size * 16))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ConvolveReal(x, y)
+			}
+		})
+	}
+}
+
 func BenchmarkFastConvolve(b *testing.B) {
 	for _, bm := range benchmarks {
 		x := complexRand(bm.size)
@@ -225,6 +664,32 @@ func BenchmarkFastConvolve(b *testing.B) {
 	}
 }
 
+// BenchmarkConvolveAuto times ConvolveAuto on either side of the threshold, for
+// finding the padded length N at which the parallel path starts to pay off
+func BenchmarkConvolveAuto(b *testing.B) {
+	defer SetParallelThreshold(0)
+	defer SetParallelism(0)
+	SetParallelism(max(2, runtime.NumCPU()))
+	for exp := 8; exp <= 20; exp += 2 {
+		N := 1 << exp
+		x := complexRand(N / 2)
+		y := complexRand(N / 2)
+		for _, mode := range []struct {
+			name      string
+			threshold int
+		}{{"serial", 1 << 62}, {"parallel", 1}} {
+			b.Run(fmt.Sprintf("N=2^%d/%s", exp, mode.name), func(b *testing.B) {
+				SetParallelThreshold(mode.threshold)
+				b.SetBytes(int64(N * 16))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ConvolveAuto(x, y)
+				}
+			})
+		}
+	}
+}
+
 var (
 	multiConvolveBenchmarks = []struct {
 		size   int
@@ -0,0 +1,123 @@
+package fft
+
+import "math/cmplx"
+
+// minCrossSpectrum is the smallest cross-spectrum magnitude that is
+// normalized, smaller bins are treated as having no energy and set to 0
+const minCrossSpectrum = 1e-300
+
+// PhaseCorrelate computes the phase correlation of x and y: the inverse FFT of
+// their cross-spectrum X*conj(Y), with each bin normalized to unit magnitude.
+// If x is a circular shift of y by d samples, so that x[n] == y[n-d], the
+// result is a sharp peak at index d, regardless of the spectra of x and y.
+// Bins with no energy are set to 0 rather than normalized.
+// This does not alter x or y, and returns a new array.
+// len(x) and len(y) must be equal, and a perfect power of 2, otherwise this will return an error.
+func PhaseCorrelate(x, y []complex128) ([]complex128, error) {
+	if err := checkZero("difference in PhaseCorrelate input vectors length", len(x)-len(y)); err != nil {
+		return nil, err
+	}
+	if err := checkLength("PhaseCorrelate input vector length", len(x)); err != nil {
+		return nil, err
+	}
+	X := ZeroPad(x, len(x))
+	Y := ZeroPad(y, len(y))
+	fft(X)
+	fft(Y)
+	for i := range X {
+		v := X[i] * cmplx.Conj(Y[i])
+		if a := cmplx.Abs(v); a > minCrossSpectrum {
+			X[i] = v / complex(a, 0)
+		} else {
+			X[i] = 0
+		}
+	}
+	ifft(X)
+	return X, nil
+}
+
+// CorrelationPeak finds the peak of a circular correlation r, such as the
+// result of PhaseCorrelate, returning the shift it represents and its magnitude.
+// Indices in the upper half of r are wrapped to negative shifts, so that
+// shift is in [-len(r)/2, (len(r)-1)/2], rounding both towards 0: [-N/2, N/2-1]
+// for even N = len(r), and [-(N-1)/2, (N-1)/2] for odd N.
+// Returns 0, 0 if r is empty.
+func CorrelationPeak(r []complex128) (shift int, value float64) {
+	peak := -1
+	for i, v := range r {
+		if a := cmplx.Abs(v); peak < 0 || a > value {
+			peak, value = i, a
+		}
+	}
+	if peak < 0 {
+		return 0, 0
+	}
+	if N := len(r); peak >= (N+1)/2 {
+		return peak - N, value
+	}
+	return peak, value
+}
+
+// FindLag finds the lag that best aligns y with x, from the peak of their linear
+// cross-correlation, sum(conj(x[n]) * y[n+lag]).
+// A positive lag means y is delayed relative to x, so that y[n] ≈ x[n-lag],
+// and a negative lag means y is ahead of x. lag is in [-(len(x)-1), len(y)-1].
+// subSample refines the peak between lags with ParabolicPeak on the correlation
+// magnitude, in [-0.5, 0.5], so the estimated delay is lag+subSample.
+// x and y may have any lengths, and are 0-padded to avoid circular wrap-around.
+// This does not alter x or y.
+// len(x) and len(y) must be positive, otherwise this will return an error.
+func FindLag(x, y []complex128) (lag int, subSample float64, err error) {
+	if err := checkPositive("FindLag x length", len(x)); err != nil {
+		return 0, 0, err
+	}
+	if err := checkPositive("FindLag y length", len(y)); err != nil {
+		return 0, 0, err
+	}
+	r := crossCorrelate(x, y)
+	N := len(r)
+	mag := Magnitude(r)
+	// Lags from -(len(x)-1) to len(y)-1, wrapping the negative lags to the end of r
+	peak := 0
+	for l := -(len(x) - 1); l < len(y); l++ {
+		if i := (l + N) % N; mag[i] > mag[peak] {
+			peak = i
+		}
+	}
+	lag = peak
+	if peak >= len(y) {
+		lag -= N
+	}
+	// The neighbours of the peak also wrap around r
+	subSample, _ = ParabolicPeak([]float64{mag[(peak+N-1)%N], mag[peak], mag[(peak+1)%N]}, 1)
+	return lag, subSample, nil
+}
+
+// crossCorrelate returns the circular cross-correlation sum(conj(x[n]) * y[n+lag])
+// of x and y, both 0-padded to the next power of 2 from len(x)+len(y)-1, so that
+// lags from -(len(x)-1) to len(y)-1 don't overlap, with negative lags at the end.
+func crossCorrelate(x, y []complex128) []complex128 {
+	N := NextPow2(len(x) + len(y) - 1)
+	X := ZeroPad(x, N)
+	Y := ZeroPad(y, N)
+	fft(X)
+	fft(Y)
+	for i := range X {
+		X[i] = cmplx.Conj(X[i]) * Y[i]
+	}
+	ifft(X)
+	return X
+}
+
+// autocorrelate returns the linear autocorrelation of the real signal x at lags 0 to len(x)-1,
+// r[lag] = sum(x[i] * x[i+lag]), 0-padding to avoid circular wrap-around.
+func autocorrelate(x []float64) []float64 {
+	z := Float64ToComplex128Array(x)
+	z = ZeroPad(z, NextPow2(2*len(x)))
+	fft(z)
+	for i, v := range z {
+		z[i] = complex(real(v)*real(v)+imag(v)*imag(v), 0)
+	}
+	ifft(z)
+	return Complex128ToFloat64Array(z[:len(x)])
+}
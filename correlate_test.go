@@ -0,0 +1,103 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPhaseCorrelate(t *testing.T) {
+	// Test mismatched and non-power of 2 lengths return InputSizeError
+	_, err := PhaseCorrelate(complexRand(16), complexRand(8))
+	checkIsInputSizeError(t, "PhaseCorrelate(complexRand(16), complexRand(8))", err)
+	_, err = PhaseCorrelate(complexRand(17), complexRand(17))
+	checkIsInputSizeError(t, "PhaseCorrelate(complexRand(17), complexRand(17))", err)
+	// Test the peak of PhaseCorrelate(x, y) is at the shift d, where x[n] == y[n-d]
+	for N := 4; N < (1 << 11); N <<= 1 {
+		for _, d := range []int{0, 1, -1, N/2 - 1, -N / 2, 3} {
+			y := complexRand(N)
+			x := copyVector(y)
+			rotateLeft(x, ((-d)%N+N)%N)
+			r, err := PhaseCorrelate(x, y)
+			if err != nil {
+				t.Errorf("PhaseCorrelate error: %v", err)
+			}
+			// Shifts wrap into [-N/2, N/2)
+			expected := (d%N + N) % N
+			if expected >= N/2 {
+				expected -= N
+			}
+			shift, value := CorrelationPeak(r)
+			if shift != expected || math.Abs(value-1) > 1e-9 {
+				t.Errorf("PhaseCorrelate peak differs: N=%d got shift=%d value=%v expected shift=%d value=1", N, shift, value, expected)
+			}
+		}
+	}
+	// Test zero inputs produce an all-zero correlation
+	r, err := PhaseCorrelate(make([]complex128, 8), complexRand(8))
+	if err != nil {
+		t.Errorf("PhaseCorrelate error: %v", err)
+	}
+	for i, v := range r {
+		if v != 0 {
+			t.Errorf("PhaseCorrelate of zeros differs: r[%d]=%v expected 0", i, v)
+		}
+	}
+	if shift, value := CorrelationPeak(nil); shift != 0 || value != 0 {
+		t.Errorf("CorrelationPeak(nil) = %d, %v, expected 0, 0", shift, value)
+	}
+	// Test the shift range at every peak index, for even and odd lengths
+	for _, N := range []int{1, 2, 5, 7, 8} {
+		lo, hi := -N/2, (N-1)/2
+		for peak := 0; peak < N; peak++ {
+			r := make([]complex128, N)
+			r[peak] = 1
+			expect := peak
+			if peak > hi {
+				expect -= N
+			}
+			shift, _ := CorrelationPeak(r)
+			if shift != expect || shift < lo || shift > hi {
+				t.Errorf("CorrelationPeak with N=%d and peak %d, got: %d, expected: %d in [%d, %d]", N, peak, shift, expect, lo, hi)
+			}
+		}
+	}
+}
+
+func TestFindLag(t *testing.T) {
+	_, _, err := FindLag(nil, complexRand(4))
+	checkIsInputSizeError(t, "FindLag(nil, complexRand(4))", err)
+	_, _, err = FindLag(complexRand(4), nil)
+	checkIsInputSizeError(t, "FindLag(complexRand(4), nil)", err)
+	// Test integer delays of y relative to x, in both directions and with unequal lengths
+	for _, d := range []int{0, 1, 5, -1, -7, 30, -30} {
+		x := complexRand(100)
+		y := make([]complex128, 120)
+		for n := range y {
+			if m := n - d; m >= 0 && m < len(x) {
+				y[n] = x[m]
+			}
+		}
+		lag, subSample, err := FindLag(x, y)
+		if err != nil {
+			t.Errorf("FindLag error: %v", err)
+		}
+		if lag != d || math.Abs(subSample) > 0.5 {
+			t.Errorf("FindLag with delay %d, got: lag=%d subSample=%v", d, lag, subSample)
+		}
+	}
+	// Test a fractional delay of a smooth pulse is refined between lags
+	pulse := func(n int, center float64) []complex128 {
+		x := make([]complex128, n)
+		for i := range x {
+			u := (float64(i) - center) / 6
+			x[i] = complex(math.Exp(-u*u), 0)
+		}
+		return x
+	}
+	for _, d := range []float64{3.3, -2.25, 10.5} {
+		lag, subSample, _ := FindLag(pulse(64, 30), pulse(64, 30+d))
+		if e := math.Abs(float64(lag) + subSample - d); e > 0.05 {
+			t.Errorf("FindLag with delay %v, got: lag=%d subSample=%v, diff=%v", d, lag, subSample, e)
+		}
+	}
+}
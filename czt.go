@@ -0,0 +1,130 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// CZT implements the chirp-z transform of x, evaluating the z-transform at the
+// m points z[k] = a*w^(-k) along a spiral contour:
+// X[k] = sum(x[n]*a^(-n)*w^(n*k)).
+// With m = len(x), a = 1 and w = exp(-2*Pi*i/len(x)) this is the DFT.
+// Uses Bluestein's algorithm, rewriting the transform as a convolution computed by FFT,
+// so len(x) and m may be of any length.
+// This does not alter x, and returns a new array. Returns nil if x is empty.
+// Takes O((N+m)*log(N+m)) run time and O(N+m) additional space.
+// m must be positive, otherwise this will return an error.
+func CZT(x []complex128, m int, w, a complex128) ([]complex128, error) {
+	if err := checkPositive("CZT output length", m); err != nil {
+		return nil, err
+	}
+	N := len(x)
+	if N == 0 {
+		return nil, nil
+	}
+	// Uses n*k = (n^2 + k^2 - (k-n)^2)/2
+	logW, logA := cmplx.Log(w), cmplx.Log(a)
+	chirp := func(t int) complex128 {
+		return cmplx.Exp(logW * complex(float64(t)*float64(t)/2, 0))
+	}
+	P := NextPow2(2*N + m - 2)
+	u := make([]complex128, P)
+	for n := 0; n < N; n++ {
+		u[n] = x[n] * cmplx.Exp(-logA*complex(float64(n), 0)) * chirp(n)
+	}
+	v := make([]complex128, P)
+	for j := 0; j < N+m-1; j++ {
+		v[j] = 1 / chirp(j-(N-1))
+	}
+	convolve(u, v)
+	y := make([]complex128, m)
+	for k := 0; k < m; k++ {
+		y[k] = chirp(k) * u[k+N-1]
+	}
+	return y, nil
+}
+
+// ZoomFFT computes the DFT of x sampled at sampleRate at m equally spaced
+// frequencies from fLow up to (but excluding) fHigh, using CZT,
+// matching scipy.signal.zoom_fft(x, [fLow, fHigh], m, fs=sampleRate).
+// This gives a high resolution view of a narrow band without a large FFT.
+// This does not alter x, and returns a new array.
+// m must be positive, otherwise this will return an error.
+func ZoomFFT(x []complex128, sampleRate, fLow, fHigh float64, m int) ([]complex128, error) {
+	w := cmplx.Rect(1, -2*math.Pi*(fHigh-fLow)/(float64(m)*sampleRate))
+	a := cmplx.Rect(1, 2*math.Pi*fLow/sampleRate)
+	return CZT(x, m, w, a)
+}
+
+// BluesteinFFT implements the discrete Fourier transform for any length,
+// using Bluestein's algorithm to rewrite it as a convolution of power of 2 length,
+// or FFT directly if len(x) is already a power of 2.
+// This does not alter x, and returns a new array. Returns nil if x is empty.
+// Takes O(N*log(N)) run time and O(N) additional space.
+func BluesteinFFT(x []complex128) ([]complex128, error) {
+	N := len(x)
+	if N == 0 {
+		return nil, nil
+	}
+	y := make([]complex128, N)
+	copy(y, x)
+	if IsPow2(N) {
+		fft(y)
+		return y, nil
+	}
+	bluestein(y)
+	return y, nil
+}
+
+// BluesteinIFFT implements the inverse discrete Fourier transform for any length,
+// by conjugating, applying BluesteinFFT, conjugating back, and scaling by 1/N,
+// so that BluesteinIFFT(BluesteinFFT(x)) == x.
+// This does not alter x, and returns a new array. Returns nil if x is empty.
+// Takes O(N*log(N)) run time and O(N) additional space.
+func BluesteinIFFT(x []complex128) ([]complex128, error) {
+	N := len(x)
+	if N == 0 {
+		return nil, nil
+	}
+	y := ZeroPad(x, N)
+	Conjugate(y)
+	if IsPow2(N) {
+		fft(y)
+	} else {
+		bluestein(y)
+	}
+	Conjugate(y)
+	invN := complex(1/float64(N), 0)
+	for i := range y {
+		y[i] *= invN
+	}
+	return y, nil
+}
+
+// bluestein does the actual work for BluesteinFFT, in-place.
+// The chirp exp(-Pi*i*t^2/N) is computed with t^2 reduced modulo 2N,
+// keeping it accurate for large N.
+func bluestein(x []complex128) {
+	N := len(x)
+	chirp := make([]complex128, N)
+	for t := range chirp {
+		s, c := math.Sincos(-math.Pi * float64((t*t)%(2*N)) / float64(N))
+		chirp[t] = complex(c, s)
+	}
+	P := NextPow2(2*N - 1)
+	u := make([]complex128, P)
+	for n := 0; n < N; n++ {
+		u[n] = x[n] * chirp[n]
+	}
+	// v[t] = conj(chirp[|t|]) for t in (-N, N), stored circularly
+	v := make([]complex128, P)
+	v[0] = cmplx.Conj(chirp[0])
+	for t := 1; t < N; t++ {
+		v[t] = cmplx.Conj(chirp[t])
+		v[P-t] = cmplx.Conj(chirp[t])
+	}
+	convolve(u, v)
+	for k := 0; k < N; k++ {
+		x[k] = chirp[k] * u[k]
+	}
+}
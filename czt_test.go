@@ -0,0 +1,97 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestCZT(t *testing.T) {
+	// Test CZT of a non-positive output length returns InputSizeError
+	_, err := CZT(complexRand(8), 0, 1, 1)
+	checkIsInputSizeError(t, "CZT(complexRand(8), 0, 1, 1)", err)
+	// Test CZT with the unit circle defaults == slowFFT, for any length
+	for N := 1; N < 100; N++ {
+		x := complexRand(N)
+		y1 := slowFFT(x)
+		y2, err := CZT(x, N, cmplx.Rect(1, -2*math.Pi/float64(N)), 1)
+		if err != nil {
+			t.Errorf("CZT error: %v", err)
+		}
+		for k := range y1 {
+			if e := cmplx.Abs(y1[k] - y2[k]); e > 1e-9 {
+				t.Errorf("slowFFT and CZT differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, k, y1[k], k, y2[k], e)
+			}
+		}
+	}
+	// Test CZT on a spiral contour == the direct z-transform
+	x := complexRand(20)
+	w, a := cmplx.Rect(1.01, -0.1), cmplx.Rect(0.9, 0.3)
+	y, err := CZT(x, 30, w, a)
+	if err != nil {
+		t.Fatalf("CZT error: %v", err)
+	}
+	for k := range y {
+		z := a * cmplx.Pow(w, complex(-float64(k), 0))
+		var expect complex128
+		for n := range x {
+			expect += x[n] * cmplx.Pow(z, complex(-float64(n), 0))
+		}
+		if e := cmplx.Abs(expect - y[k]); e > 1e-9*cmplx.Abs(expect) {
+			t.Errorf("CZT on spiral differs: y[%d]=%v expected=%v diff=%v", k, y[k], expect, e)
+		}
+	}
+}
+
+func TestZoomFFT(t *testing.T) {
+	sampleRate := 1000.0
+	x := complexRand(64)
+	fLow, fHigh, m := 100.0, 150.0, 25
+	y, err := ZoomFFT(x, sampleRate, fLow, fHigh, m)
+	if err != nil {
+		t.Fatalf("ZoomFFT error: %v", err)
+	}
+	for k := range y {
+		f := fLow + float64(k)*(fHigh-fLow)/float64(m)
+		var expect complex128
+		for n := range x {
+			expect += x[n] * cmplx.Rect(1, -2*math.Pi*f*float64(n)/sampleRate)
+		}
+		if e := cmplx.Abs(expect - y[k]); e > 1e-9 {
+			t.Errorf("ZoomFFT differs: f=%v y[%d]=%v expected=%v diff=%v", f, k, y[k], expect, e)
+		}
+	}
+}
+
+func TestBluestein(t *testing.T) {
+	// Test empty inputs return nil
+	if y, err := BluesteinFFT(nil); y != nil || err != nil {
+		t.Errorf("BluesteinFFT(nil), got: %v, %v, expected: nil, nil", y, err)
+	}
+	if y, err := BluesteinIFFT(nil); y != nil || err != nil {
+		t.Errorf("BluesteinIFFT(nil), got: %v, %v, expected: nil, nil", y, err)
+	}
+	// Test BluesteinFFT(x) == slowFFT(x) and BluesteinIFFT(BluesteinFFT(x)) == x for prime and composite lengths
+	for _, N := range []int{1, 2, 3, 5, 6, 7, 12, 13, 16, 31, 60, 97, 100, 127, 360, 1009} {
+		x := complexRand(N)
+		y1 := slowFFT(x)
+		y2, err := BluesteinFFT(x)
+		if err != nil {
+			t.Errorf("BluesteinFFT error: %v", err)
+		}
+		for k := range y1 {
+			if e := cmplx.Abs(y1[k] - y2[k]); e > 1e-9 {
+				t.Errorf("slowFFT and BluesteinFFT differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, k, y1[k], k, y2[k], e)
+			}
+		}
+		z, err := BluesteinIFFT(y2)
+		if err != nil {
+			t.Errorf("BluesteinIFFT error: %v", err)
+		}
+		for n := range x {
+			if e := cmplx.Abs(x[n] - z[n]); e > 1e-9 {
+				t.Errorf("BluesteinIFFT(BluesteinFFT(x)) differs: N=%d x[%d]=%v z[%d]=%v diff=%v", N, n, x[n], n, z[n], e)
+			}
+		}
+	}
+}
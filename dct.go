@@ -0,0 +1,69 @@
+package fft
+
+import (
+	"math"
+)
+
+// DCT implements the orthonormal discrete cosine transform (DCT-II) of real input,
+// matching scipy.fft.dct(x, norm="ortho").
+// The even extension of x is folded into a single complex FFT of length len(x)
+// by reordering the even and odd indexed samples (Makhoul's method).
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func DCT(x []float64) ([]float64, error) {
+	if err := checkLength("DCT Input", len(x)); err != nil {
+		return nil, err
+	}
+	N := len(x)
+	v := make([]complex128, N)
+	for n := 0; n < N/2; n++ {
+		v[n] = complex(x[2*n], 0)
+		v[N-1-n] = complex(x[2*n+1], 0)
+	}
+	if N == 1 {
+		v[0] = complex(x[0], 0)
+	}
+	fft(v)
+	y := make([]float64, N)
+	y[0] = real(v[0]) * math.Sqrt(1/float64(N))
+	s := math.Sqrt(2 / float64(N))
+	for k := 1; k < N; k++ {
+		sin, cos := math.Sincos(-math.Pi * float64(k) / float64(2*N))
+		y[k] = s * (real(v[k])*cos - imag(v[k])*sin)
+	}
+	return y, nil
+}
+
+// IDCT implements the orthonormal inverse discrete cosine transform (DCT-III) of real input,
+// matching scipy.fft.idct(x, norm="ortho"), so that IDCT(DCT(x)) == x.
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IDCT(x []float64) ([]float64, error) {
+	if err := checkLength("IDCT Input", len(x)); err != nil {
+		return nil, err
+	}
+	N := len(x)
+	// Undo the orthonormal scaling
+	S := make([]float64, N+1)
+	S[0] = x[0] * math.Sqrt(float64(N))
+	for k := 1; k < N; k++ {
+		S[k] = x[k] * math.Sqrt(float64(N)/2)
+	}
+	v := make([]complex128, N)
+	for k := 0; k < N; k++ {
+		sin, cos := math.Sincos(math.Pi * float64(k) / float64(2*N))
+		v[k] = complex(cos, sin) * complex(S[k], -S[N-k])
+	}
+	ifft(v)
+	y := make([]float64, N)
+	for n := 0; n < N/2; n++ {
+		y[2*n] = real(v[n])
+		y[2*n+1] = real(v[N-1-n])
+	}
+	if N == 1 {
+		y[0] = real(v[0])
+	}
+	return y, nil
+}
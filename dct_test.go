@@ -0,0 +1,54 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+// slowDCT is the direct O(N^2) orthonormal DCT-II, for testing purposes
+func slowDCT(x []float64) []float64 {
+	N := len(x)
+	y := make([]float64, N)
+	for k := 0; k < N; k++ {
+		for n := 0; n < N; n++ {
+			y[k] += x[n] * math.Cos(math.Pi*float64(k)*float64(2*n+1)/float64(2*N))
+		}
+		if k == 0 {
+			y[k] *= math.Sqrt(1 / float64(N))
+		} else {
+			y[k] *= math.Sqrt(2 / float64(N))
+		}
+	}
+	return y
+}
+
+func TestDCT(t *testing.T) {
+	// Test DCT and IDCT of non-powers of 2 returns InputSizeError
+	_, err := DCT(floatRand(17))
+	checkIsInputSizeError(t, "DCT(floatRand(17))", err)
+	_, err = IDCT(floatRand(17))
+	checkIsInputSizeError(t, "IDCT(floatRand(17))", err)
+	// Test DCT(x) == slowDCT(x) and IDCT(DCT(x)) == x for power of 2 up to 2^10
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N)
+		y1 := slowDCT(x)
+		y2, err := DCT(x)
+		if err != nil {
+			t.Errorf("DCT error: %v", err)
+		}
+		for k := 0; k < N; k++ {
+			if e := math.Abs(y1[k] - y2[k]); e > 1e-9 {
+				t.Errorf("slowDCT and DCT differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, k, y1[k], k, y2[k], e)
+			}
+		}
+		z, err := IDCT(y2)
+		if err != nil {
+			t.Errorf("IDCT error: %v", err)
+		}
+		for n := 0; n < N; n++ {
+			if e := math.Abs(x[n] - z[n]); e > 1e-9 {
+				t.Errorf("IDCT(DCT(x)) differs: N=%d x[%d]=%v z[%d]=%v diff=%v", N, n, x[n], n, z[n], e)
+			}
+		}
+	}
+}
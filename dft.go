@@ -0,0 +1,38 @@
+package fft
+
+// DFT implements the discrete Fourier transform directly from its definition,
+// for any length. This is useful as a reference to check other transforms
+// against, and can be faster than padding for very small non-power of 2 lengths.
+// This does not alter x, and returns a new array.
+// Takes O(N^2) run time and O(N) additional space.
+func DFT(x []complex128) []complex128 {
+	return dft(x, TwiddleFactors(len(x)))
+}
+
+// IDFT implements the inverse discrete Fourier transform directly from its definition,
+// for any length, so that IDFT(DFT(x)) == x.
+// This does not alter x, and returns a new array.
+// Takes O(N^2) run time and O(N) additional space.
+func IDFT(x []complex128) []complex128 {
+	N := len(x)
+	w := TwiddleFactors(N)
+	Conjugate(w)
+	y := dft(x, w)
+	scale(y, 1/float64(N))
+	return y
+}
+
+// dft does the actual work for DFT and IDFT, using the roots of unity w.
+// Indexing w with k*n modulo N avoids the rounding error of large angles.
+func dft(x, w []complex128) []complex128 {
+	N := len(x)
+	y := make([]complex128, N)
+	for k := 0; k < N; k++ {
+		var s complex128
+		for n := 0; n < N; n++ {
+			s += x[n] * w[(k*n)%N]
+		}
+		y[k] = s
+	}
+	return y
+}
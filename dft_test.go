@@ -0,0 +1,40 @@
+package fft
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestDFT(t *testing.T) {
+	if y := DFT(nil); len(y) != 0 {
+		t.Errorf("DFT(nil), got: %v, expected: []", y)
+	}
+	// Test DFT(x) == slowFFT(x) and IDFT(DFT(x)) == x for any length
+	for N := 1; N < 100; N++ {
+		x := complexRand(N)
+		y1 := slowFFT(x)
+		y2 := DFT(x)
+		for k := range y1 {
+			if e := cmplx.Abs(y1[k] - y2[k]); e > 1e-9 {
+				t.Errorf("slowFFT and DFT differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, k, y1[k], k, y2[k], e)
+			}
+		}
+		z := IDFT(y2)
+		for n := range x {
+			if e := cmplx.Abs(x[n] - z[n]); e > 1e-9 {
+				t.Errorf("IDFT(DFT(x)) differs: N=%d x[%d]=%v z[%d]=%v diff=%v", N, n, x[n], n, z[n], e)
+			}
+		}
+	}
+	// Test DFT(x) == FFT(x) for powers of 2
+	for N := 1; N < (1 << 10); N <<= 1 {
+		x := complexRand(N)
+		y := DFT(x)
+		FFT(x)
+		for k := range x {
+			if e := cmplx.Abs(x[k] - y[k]); e > 1e-9 {
+				t.Errorf("FFT and DFT differ: N=%d x[%d]=%v y[%d]=%v diff=%v", N, k, x[k], k, y[k], e)
+			}
+		}
+	}
+}
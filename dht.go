@@ -0,0 +1,43 @@
+package fft
+
+// DHT implements the discrete Hartley transform of real input,
+// H[k] = sum(x[n]*cas(2*Pi*k*n/N)), where cas(t) = cos(t) + sin(t).
+// This is computed from the FFT of x as H[k] = Re(X[k]) - Im(X[k]).
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func DHT(x []float64) ([]float64, error) {
+	if err := checkLength("DHT Input", len(x)); err != nil {
+		return nil, err
+	}
+	return dht(x), nil
+}
+
+// IDHT implements the inverse discrete Hartley transform of real input,
+// so that IDHT(DHT(x)) == x.
+// The DHT is its own inverse up to a scale factor of 1/N.
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IDHT(x []float64) ([]float64, error) {
+	if err := checkLength("IDHT Input", len(x)); err != nil {
+		return nil, err
+	}
+	y := dht(x)
+	invN := 1 / float64(len(x))
+	for i := range y {
+		y[i] *= invN
+	}
+	return y, nil
+}
+
+// dht does the actual work for DHT and IDHT
+func dht(x []float64) []float64 {
+	v := Float64ToComplex128Array(x)
+	fft(v)
+	y := make([]float64, len(x))
+	for k, c := range v {
+		y[k] = real(c) - imag(c)
+	}
+	return y
+}
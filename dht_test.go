@@ -0,0 +1,55 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+// slowDHT is the direct O(N^2) discrete Hartley transform, for testing purposes
+func slowDHT(x []float64) []float64 {
+	N := len(x)
+	y := make([]float64, N)
+	for k := 0; k < N; k++ {
+		for n := 0; n < N; n++ {
+			s, c := math.Sincos(2 * math.Pi * float64(k*n) / float64(N))
+			y[k] += x[n] * (c + s)
+		}
+	}
+	return y
+}
+
+func TestDHT(t *testing.T) {
+	// Test DHT and IDHT of non-powers of 2 returns InputSizeError
+	_, err := DHT(floatRand(17))
+	checkIsInputSizeError(t, "DHT(floatRand(17))", err)
+	_, err = IDHT(floatRand(17))
+	checkIsInputSizeError(t, "IDHT(floatRand(17))", err)
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N)
+		// Test DHT(x) == slowDHT(x)
+		y1 := slowDHT(x)
+		y2, err := DHT(x)
+		if err != nil {
+			t.Errorf("DHT error: %v", err)
+		}
+		for k := range y1 {
+			if e := math.Abs(y1[k] - y2[k]); e > 1e-9 {
+				t.Errorf("slowDHT and DHT differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, k, y1[k], k, y2[k], e)
+			}
+		}
+		// Test DHT(DHT(x)) == N*x, and IDHT(DHT(x)) == x
+		z1, _ := DHT(y2)
+		z2, err := IDHT(y2)
+		if err != nil {
+			t.Errorf("IDHT error: %v", err)
+		}
+		for n := range x {
+			if e := math.Abs(float64(N)*x[n] - z1[n]); e > 1e-9*float64(N) {
+				t.Errorf("DHT(DHT(x)) differs from N*x: N=%d x[%d]=%v z1[%d]=%v diff=%v", N, n, x[n], n, z1[n], e)
+			}
+			if e := math.Abs(x[n] - z2[n]); e > 1e-9 {
+				t.Errorf("IDHT(DHT(x)) differs: N=%d x[%d]=%v z2[%d]=%v diff=%v", N, n, x[n], n, z2[n], e)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package fft
+
+// DST implements the discrete sine transform (DST-I) of real input,
+// y[k] = 2*sum(x[n]*sin(Pi*(k+1)*(n+1)/(N+1))), matching scipy.fft.dst(x, type=1).
+// The odd extension of x, of length 2*(len(x)+1), is transformed with FFT.
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x)+1 must be a perfect power of 2, otherwise this will return an error.
+func DST(x []float64) ([]float64, error) {
+	if err := checkLength("DST Input length + 1", len(x)+1); err != nil {
+		return nil, err
+	}
+	return dst(x), nil
+}
+
+// IDST implements the inverse discrete sine transform (DST-I) of real input,
+// matching scipy.fft.idst(x, type=1), so that IDST(DST(x)) == x.
+// The DST-I is its own inverse up to a scale factor of 1/(2*(len(x)+1)).
+// This does not alter x, and returns a new array.
+// Requires O(N) additional memory.
+// len(x)+1 must be a perfect power of 2, otherwise this will return an error.
+func IDST(x []float64) ([]float64, error) {
+	if err := checkLength("IDST Input length + 1", len(x)+1); err != nil {
+		return nil, err
+	}
+	y := dst(x)
+	s := 1 / float64(2*(len(x)+1))
+	for i := range y {
+		y[i] *= s
+	}
+	return y, nil
+}
+
+// dst does the actual work for DST and IDST
+func dst(x []float64) []float64 {
+	N := len(x)
+	if N == 0 {
+		return []float64{}
+	}
+	// Odd extension: [0, x, 0, -reverse(x)]
+	v := make([]complex128, 2*(N+1))
+	for n := 0; n < N; n++ {
+		v[n+1] = complex(x[n], 0)
+		v[2*(N+1)-1-n] = complex(-x[n], 0)
+	}
+	fft(v)
+	y := make([]float64, N)
+	for k := 0; k < N; k++ {
+		y[k] = -imag(v[k+1])
+	}
+	return y
+}
@@ -0,0 +1,43 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDST(t *testing.T) {
+	// Test DST and IDST of lengths not one less than a power of 2 returns InputSizeError
+	_, err := DST(floatRand(4))
+	checkIsInputSizeError(t, "DST(floatRand(4))", err)
+	_, err = IDST(floatRand(4))
+	checkIsInputSizeError(t, "IDST(floatRand(4))", err)
+	// Test a hand-computed case
+	s2 := math.Sqrt2
+	expect := []float64{4*s2 + 4, -4, 4*s2 - 4}
+	got, err := DST([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("DST error: %v", err)
+	}
+	for k := range expect {
+		if e := math.Abs(got[k] - expect[k]); e > 1e-9 {
+			t.Errorf("DST([1, 2, 3]), got: y[%d] = %v, expected: y[%d] = %v", k, got[k], k, expect[k])
+		}
+	}
+	// Test IDST(DST(x)) == x
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N - 1)
+		y, err := DST(x)
+		if err != nil {
+			t.Errorf("DST error: %v", err)
+		}
+		z, err := IDST(y)
+		if err != nil {
+			t.Errorf("IDST error: %v", err)
+		}
+		for n := range x {
+			if e := math.Abs(x[n] - z[n]); e > 1e-9 {
+				t.Errorf("IDST(DST(x)) differs: N=%d x[%d]=%v z[%d]=%v diff=%v", N-1, n, x[n], n, z[n], e)
+			}
+		}
+	}
+}
@@ -1,32 +1,115 @@
 package fft
 
 import (
+	"errors"
 	"fmt"
 )
 
-// InputSizeError represents an error when an input vector's size is not a power of 2.
+// Sentinel errors for each kind of size requirement, which an InputSizeError
+// wraps, so that callers can branch on the kind with errors.Is.
+var (
+	// ErrNotPow2 is wrapped when a size must be a power of 2.
+	ErrNotPow2 = errors.New("not a power of 2")
+	// ErrLengthMismatch is wrapped when two sizes must be equal.
+	ErrLengthMismatch = errors.New("length mismatch")
+	// ErrNotPositive is wrapped when a size must be positive.
+	ErrNotPositive = errors.New("not positive")
+	// ErrOutOfRange is wrapped when a size must be at least or at most some bound.
+	ErrOutOfRange = errors.New("out of range")
+	// ErrOddLength is wrapped when a size must be even.
+	ErrOddLength = errors.New("odd length")
+)
+
+// InputSizeError represents an error when an input vector's size is invalid,
+// such as not being a power of 2.
+// Err is the sentinel error for the kind of Requirement, such as ErrNotPow2,
+// and is matched by errors.Is.
 type InputSizeError struct {
 	Context     string
 	Requirement string
 	Size        int
+	Err         error
 }
 
 func (e *InputSizeError) Error() string {
 	return fmt.Sprintf("Size of %s must be %s, is: %d", e.Context, e.Requirement, e.Size)
 }
 
+// Unwrap returns the sentinel error for the kind of Requirement.
+func (e *InputSizeError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidInputError represents an error when an input vector contains a NaN or infinite value.
+type InvalidInputError struct {
+	Context string
+	Index   int
+	Value   complex128
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("Value of %s must be finite, is: %v at index %d", e.Context, e.Value, e.Index)
+}
+
+// ParameterError represents an error when a scalar parameter is invalid,
+// such as a negative regularization constant.
+// Err is the sentinel error for the kind of Requirement, and is matched by errors.Is.
+type ParameterError struct {
+	Context     string
+	Requirement string
+	Value       float64
+	Err         error
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("Value of %s must be %s, is: %v", e.Context, e.Requirement, e.Value)
+}
+
+// Unwrap returns the sentinel error for the kind of Requirement.
+func (e *ParameterError) Unwrap() error {
+	return e.Err
+}
+
+// checkNonNegative checks that v is at least 0, rejecting NaN
+func checkNonNegative(Context string, v float64) error {
+	if !(v >= 0) {
+		return &ParameterError{Context: Context, Requirement: "at least 0", Value: v, Err: ErrOutOfRange}
+	}
+	return nil
+}
+
 // checkLength checks that the length of x is a valid power of 2
 func checkLength(Context string, N int) error {
 	if !IsPow2(N) {
-		return &InputSizeError{Context: Context, Requirement: "power of 2", Size: N}
+		return &InputSizeError{Context: Context, Requirement: "power of 2", Size: N, Err: ErrNotPow2}
+	}
+	// IsPow2 accepts math.MinInt, which is what 2*MaxLength overflows to
+	if N < 0 {
+		return &InputSizeError{Context: Context, Requirement: fmt.Sprintf("power of 2 at most %d", MaxLength), Size: N, Err: ErrOutOfRange}
 	}
 	return nil
 }
 
-// checkLength checks that the length of x is a valid power of 2
+// checkZero checks that N, a difference of lengths, is zero
 func checkZero(Context string, N int) error {
 	if N != 0 {
-		return &InputSizeError{Context: Context, Requirement: "zero", Size: N}
+		return &InputSizeError{Context: Context, Requirement: "zero", Size: N, Err: ErrLengthMismatch}
+	}
+	return nil
+}
+
+// checkPositive checks that N is strictly positive
+func checkPositive(Context string, N int) error {
+	if N <= 0 {
+		return &InputSizeError{Context: Context, Requirement: "positive", Size: N, Err: ErrNotPositive}
+	}
+	return nil
+}
+
+// checkFinite checks that every value in x is finite
+func checkFinite(Context string, x []complex128) error {
+	if i := firstInvalid(x); i >= 0 {
+		return &InvalidInputError{Context: Context, Index: i, Value: x[i]}
 	}
 	return nil
 }
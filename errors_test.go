@@ -1,11 +1,13 @@
 package fft
 
 import (
+	"errors"
+	"math"
 	"testing"
 )
 
 func TestInputSizeError(t *testing.T) {
-	e := &InputSizeError{"asdf", "qwer", 5}
+	e := &InputSizeError{Context: "asdf", Requirement: "qwer", Size: 5}
 	expect := "Size of asdf must be qwer, is: 5"
 	got := e.Error()
 	if expect != got {
@@ -13,6 +15,52 @@ func TestInputSizeError(t *testing.T) {
 	}
 }
 
+func TestInputSizeErrorIs(t *testing.T) {
+	_, zeroPadErr := ZeroPadTo(complexRand(8), 4)
+	_, interleavedErr := InterleavedToComplex128(floatRand(3))
+	_, stftErr := STFT(floatRand(16), 8, 0, Hanning)
+	for _, c := range []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"FFT(complexRand(17))", FFT(complexRand(17)), ErrNotPow2},
+		{"FastConvolve(complexRand(8), complexRand(4))", FastConvolve(complexRand(8), complexRand(4)), ErrLengthMismatch},
+		{"STFT(floatRand(16), 8, 0, Hanning)", stftErr, ErrNotPositive},
+		{"ZeroPadTo(complexRand(8), 4)", zeroPadErr, ErrOutOfRange},
+		{"InterleavedToComplex128(floatRand(3))", interleavedErr, ErrOddLength},
+	} {
+		if !errors.Is(c.err, c.target) {
+			t.Errorf("%s returned %v, expected errors.Is(err, %v)", c.name, c.err, c.target)
+		}
+		var e *InputSizeError
+		if !errors.As(c.err, &e) {
+			t.Errorf("%s returned %v, expected an *InputSizeError", c.name, c.err)
+		}
+	}
+	if err := FFT(complexRand(17)); errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("FFT(complexRand(17)) returned %v, which shouldn't match ErrLengthMismatch", err)
+	}
+}
+
+func TestInvalidInputError(t *testing.T) {
+	e := &InvalidInputError{"asdf", 3, complex(math.NaN(), 1)}
+	expect := "Value of asdf must be finite, is: (NaN+1i) at index 3"
+	got := e.Error()
+	if expect != got {
+		t.Errorf("InvalidInputError.Error(), expected %s, got %s", expect, got)
+	}
+}
+
+func TestParameterError(t *testing.T) {
+	e := &ParameterError{Context: "asdf", Requirement: "at least 0", Value: -0.5}
+	expect := "Value of asdf must be at least 0, is: -0.5"
+	got := e.Error()
+	if expect != got {
+		t.Errorf("ParameterError.Error(), expected %s, got %s", expect, got)
+	}
+}
+
 func checkIsInputSizeError(t *testing.T, context string, err error) {
 	if err == nil {
 		t.Errorf("%s didn't return error", context)
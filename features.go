@@ -0,0 +1,134 @@
+package fft
+
+import "math"
+
+// binFreq returns the frequency of bin k of a one-sided spectrum of n bins,
+// covering 0 to sampleRate/2 inclusive
+func binFreq(k, n int, sampleRate float64) float64 {
+	if n < 2 {
+		return 0
+	}
+	return float64(k) * sampleRate / float64(2*(n-1))
+}
+
+// SpectralCentroid returns the center of mass of the spectrum mag in Hz,
+// the mag-weighted mean of the bin frequencies.
+// mag is a one-sided magnitude or power spectrum, holding the bins from 0 to
+// sampleRate/2 inclusive, as returned by RFFTMagnitude.
+// Returns 0 if mag is empty or all 0s.
+func SpectralCentroid(mag []float64, sampleRate float64) float64 {
+	var weighted, total float64
+	for k, v := range mag {
+		weighted += binFreq(k, len(mag), sampleRate) * v
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// SpectralRolloff returns the frequency in Hz of the lowest bin of the spectrum
+// mag at or below which fraction (such as 0.85) of the total lies.
+// mag is a one-sided magnitude or power spectrum, holding the bins from 0 to
+// sampleRate/2 inclusive, as returned by RFFTMagnitude.
+// Returns 0 if mag is empty or all 0s.
+func SpectralRolloff(mag []float64, sampleRate, fraction float64) float64 {
+	var total float64
+	for _, v := range mag {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	threshold := fraction * total
+	var cumulative float64
+	for k, v := range mag {
+		cumulative += v
+		if cumulative >= threshold {
+			return binFreq(k, len(mag), sampleRate)
+		}
+	}
+	return binFreq(len(mag)-1, len(mag), sampleRate)
+}
+
+// SpectralFlatness returns the Wiener entropy of the spectrum mag, the ratio of
+// its geometric mean to its arithmetic mean, in [0, 1]. A flat (noise-like)
+// spectrum has a flatness of 1, and a peaked (tonal) spectrum is close to 0.
+// Returns 0 if mag is empty, or contains any 0s.
+func SpectralFlatness(mag []float64) float64 {
+	if len(mag) == 0 {
+		return 0
+	}
+	var logSum, total float64
+	for _, v := range mag {
+		if v <= 0 {
+			return 0
+		}
+		logSum += math.Log(v)
+		total += v
+	}
+	n := float64(len(mag))
+	return math.Exp(logSum/n) / (total / n)
+}
+
+// hzToMel converts a frequency in Hz to the mel scale, using the HTK formula
+func hzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// melToHz converts a frequency on the mel scale back to Hz
+func melToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+// MelFilterbank returns the weights of numMel triangular filters spaced evenly on the
+// mel scale from fMin to fMax Hz, for re-binning a one-sided spectrum of numBins bins,
+// covering 0 to sampleRate/2 inclusive, such as from RFFTMagnitude, with ApplyFilterbank.
+// Filter m rises linearly from 0 at the center of filter m-1 to 1 at its own center,
+// and falls back to 0 at the center of filter m+1, with fMin and fMax as the outer edges.
+// The weights are computed at each bin's exact frequency, so narrow filters at low
+// frequencies may fall between bins and have all 0 weights if numBins is small.
+// The mel scale is the HTK one, 2595*log10(1+f/700).
+// Returns a numMel×numBins matrix, with all the rows sharing one backing array.
+// Returns nil if numBins < 2, numMel is not positive, or unless 0 <= fMin < fMax <= sampleRate/2.
+func MelFilterbank(numBins, numMel int, sampleRate, fMin, fMax float64) [][]float64 {
+	if numBins < 2 || numMel <= 0 || !(fMin >= 0 && fMin < fMax && fMax <= sampleRate/2) {
+		return nil
+	}
+	// The edges of the filters, evenly spaced in mels, with filter m spanning edges[m] to edges[m+2]
+	edges := make([]float64, numMel+2)
+	lo, hi := hzToMel(fMin), hzToMel(fMax)
+	for i := range edges {
+		edges[i] = melToHz(lo + (hi-lo)*float64(i)/float64(numMel+1))
+	}
+	data := make([]float64, numMel*numBins)
+	fb := make([][]float64, numMel)
+	for m := range fb {
+		fb[m] = data[m*numBins : (m+1)*numBins : (m+1)*numBins]
+		left, center, right := edges[m], edges[m+1], edges[m+2]
+		for k := range fb[m] {
+			f := binFreq(k, numBins, sampleRate)
+			if f > left && f <= center {
+				fb[m][k] = (f - left) / (center - left)
+			} else if f > center && f < right {
+				fb[m][k] = (right - f) / (right - center)
+			}
+		}
+	}
+	return fb
+}
+
+// ApplyFilterbank re-bins spectrum, such as a power spectrum, through the filterbank fb,
+// such as from MelFilterbank, returning the weighted sum of the bins for each filter,
+// out[m] = sum(fb[m][k] * spectrum[k]), in a new array.
+// Bins beyond the shorter of spectrum and fb[m] are ignored.
+func ApplyFilterbank(spectrum []float64, fb [][]float64) []float64 {
+	out := make([]float64, len(fb))
+	for m, weights := range fb {
+		for k := 0; k < min(len(weights), len(spectrum)); k++ {
+			out[m] += weights[k] * spectrum[k]
+		}
+	}
+	return out
+}
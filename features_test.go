@@ -0,0 +1,124 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpectralFeatures(t *testing.T) {
+	// 9 bins cover 0 to 8000 Hz at a sample rate of 16000, 1000 Hz apart
+	fs := 16000.0
+	flat := []float64{1, 1, 1, 1, 1, 1, 1, 1, 1}
+	peaked := []float64{0, 0, 0, 5, 0, 0, 0, 0, 0}
+	zeros := make([]float64, 9)
+	for _, c := range []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"SpectralCentroid(flat)", SpectralCentroid(flat, fs), 4000},
+		{"SpectralCentroid(peaked)", SpectralCentroid(peaked, fs), 3000},
+		{"SpectralCentroid(zeros)", SpectralCentroid(zeros, fs), 0},
+		{"SpectralCentroid(nil)", SpectralCentroid(nil, fs), 0},
+		{"SpectralRolloff(flat, 0.5)", SpectralRolloff(flat, fs, 0.5), 4000},
+		{"SpectralRolloff(flat, 1)", SpectralRolloff(flat, fs, 1), 8000},
+		{"SpectralRolloff(peaked, 0.85)", SpectralRolloff(peaked, fs, 0.85), 3000},
+		{"SpectralRolloff(zeros, 0.85)", SpectralRolloff(zeros, fs, 0.85), 0},
+		{"SpectralFlatness(flat)", SpectralFlatness(flat), 1},
+		{"SpectralFlatness(peaked)", SpectralFlatness(peaked), 0},
+		{"SpectralFlatness({1, 4})", SpectralFlatness([]float64{1, 4}), 0.8},
+		{"SpectralFlatness(nil)", SpectralFlatness(nil), 0},
+	} {
+		if e := math.Abs(c.got - c.expected); e > 1e-9 {
+			t.Errorf("%s differs: got=%v expected=%v diff=%v", c.name, c.got, c.expected, e)
+		}
+	}
+}
+
+func TestMelFilterbank(t *testing.T) {
+	if e := math.Abs(hzToMel(1000) - 1000); e > 0.1 {
+		t.Errorf("hzToMel(1000), got: %v, expected about 1000", hzToMel(1000))
+	}
+	for _, f := range []float64{0, 440, 8000} {
+		if e := math.Abs(melToHz(hzToMel(f)) - f); e > 1e-9 {
+			t.Errorf("melToHz(hzToMel(%v)) differs: diff=%v", f, e)
+		}
+	}
+	// Test invalid arguments return nil
+	for _, fb := range [][][]float64{
+		MelFilterbank(1, 10, 16000, 0, 8000),
+		MelFilterbank(257, 0, 16000, 0, 8000),
+		MelFilterbank(257, 10, 16000, 4000, 2000),
+		MelFilterbank(257, 10, 16000, 0, 9000),
+	} {
+		if fb != nil {
+			t.Errorf("MelFilterbank with invalid arguments, expected nil")
+		}
+	}
+	fs := 16000.0
+	numBins, numMel := 513, 40
+	fb := MelFilterbank(numBins, numMel, fs, 100, 8000)
+	if len(fb) != numMel {
+		t.Fatalf("MelFilterbank filter count, got: %d, expected: %d", len(fb), numMel)
+	}
+	lo, hi := hzToMel(100), hzToMel(8000)
+	center := func(m int) float64 { return melToHz(lo + (hi-lo)*float64(m+1)/float64(numMel+1)) }
+	for m, row := range fb {
+		if len(row) != numBins {
+			t.Errorf("MelFilterbank filter %d length, got: %d, expected: %d", m, len(row), numBins)
+		}
+		// Test each filter is a triangle in [0, 1], peaking at the bin nearest its center
+		peak := 0
+		for k, w := range row {
+			if w < 0 || w > 1 {
+				t.Errorf("MelFilterbank weight out of range: fb[%d][%d] = %v", m, k, w)
+			}
+			if w > row[peak] {
+				peak = k
+			}
+		}
+		if e := math.Abs(binFreq(peak, numBins, fs) - center(m)); e > fs/float64(2*(numBins-1)) {
+			t.Errorf("MelFilterbank filter %d peaks at %vHz, expected about %vHz", m, binFreq(peak, numBins, fs), center(m))
+		}
+	}
+	// Test the filters sum to 1 between the first and last centers, where they overlap
+	for k := 0; k < numBins; k++ {
+		f := binFreq(k, numBins, fs)
+		if f < center(0) || f > center(numMel-1) {
+			continue
+		}
+		s := 0.0
+		for m := range fb {
+			s += fb[m][k]
+		}
+		if e := math.Abs(s - 1); e > 1e-9 {
+			t.Errorf("MelFilterbank weights at %vHz sum to %v, expected 1", f, s)
+		}
+	}
+}
+
+func TestApplyFilterbank(t *testing.T) {
+	fb := [][]float64{{1, 0.5, 0}, {0, 0.5, 1, 1}}
+	got := ApplyFilterbank([]float64{2, 4, 6}, fb)
+	expect := []float64{4, 8}
+	for i := range expect {
+		if math.Abs(got[i]-expect[i]) > 1e-9 {
+			t.Errorf("ApplyFilterbank, got: %v, expected: %v", got, expect)
+		}
+	}
+	// Test a mel spectrum of a tone is largest in the filter around it
+	fs := 16000.0
+	X := Float64ToComplex128Array(tone(1024, 1000, fs, 1))
+	fft(X)
+	mel := ApplyFilterbank(PSD(X, Rectangular, fs), MelFilterbank(513, 40, fs, 0, 8000))
+	peak := 0
+	for m, v := range mel {
+		if v > mel[peak] {
+			peak = m
+		}
+	}
+	// Filter m is centered on mel (m+1)/41 of the way to 8000Hz
+	if c := melToHz(hzToMel(8000) * float64(peak+1) / 41); math.Abs(c-1000) > 100 {
+		t.Errorf("ApplyFilterbank of a 1000Hz tone peaks in the filter centered at %vHz", c)
+	}
+}
@@ -5,15 +5,17 @@
 //
 // The algorithm is non-recursive, works in-place overwriting
 // the input array, and requires O(1) additional space.
+// No permutation or twiddle tables are cached between calls, so even a
+// very large transform leaves no memory behind once it returns.
 package fft
 
 import (
+	"math"
 	"math/bits"
-	"math/cmplx"
 )
 
 // Prepare precomputes values used for FFT on a vector of length N.
-// N must be a perfect power of 2, otherwise this will return an error.
+// N must be a perfect power of 2 of at most MaxLength, otherwise this will return an error.
 //
 // Deprecated: This no longer has any functionality
 func Prepare(N int) error {
@@ -32,11 +34,39 @@ func FFT(x []complex128) error {
 	return nil
 }
 
+// FFTChecked implements the fast Fourier transform, like FFT, but first scans x
+// for NaN or infinite values, which would otherwise spread through the whole output.
+// This is done in-place (modifying the input array), and x is left unchanged on error.
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an InputSizeError.
+// Every value in x must be finite, otherwise this will return an InvalidInputError
+// with the index of the first invalid value.
+func FFTChecked(x []complex128) error {
+	if err := checkLength("FFT Input", len(x)); err != nil {
+		return err
+	}
+	if err := checkFinite("FFT Input", x); err != nil {
+		return err
+	}
+	fft(x)
+	return nil
+}
+
 // FFTSinglePrecision implements the fast Fourier transform. In Float32 Format
 // This is done in-place (modifying the input array).
 // Requires O(1) additional memory.
 // len(x) must be a perfect power of 2, otherwise this will return an error.
+//
+// This is equivalent to FFT32.
 func FFTSinglePrecision(x []complex64) error {
+	return FFT32(x)
+}
+
+// FFT32 implements the fast Fourier transform natively on complex64 data.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func FFT32(x []complex64) error {
 	if err := checkLength("FFT Input", len(x)); err != nil {
 		return err
 	}
@@ -44,6 +74,18 @@ func FFTSinglePrecision(x []complex64) error {
 	return nil
 }
 
+// IFFT32 implements the inverse fast Fourier transform natively on complex64 data.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IFFT32(x []complex64) error {
+	if err := checkLength("IFFT Input", len(x)); err != nil {
+		return err
+	}
+	ifft64(x)
+	return nil
+}
+
 // IFFT implements the inverse fast Fourier transform.
 // This is done in-place (modifying the input array).
 // Requires O(1) additional memory.
@@ -56,47 +98,182 @@ func IFFT(x []complex128) error {
 	return nil
 }
 
+// IFFTReal implements the inverse fast Fourier transform for a spectrum whose
+// inverse is known to be real, such as a conjugate-symmetric one, returning only
+// the real parts of the result in a new array. The imaginary parts are
+// discarded during the final scaling pass, rather than in a separate pass.
+// This is done in-place (modifying the input array, which is left holding the
+// unscaled transform).
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IFFTReal(x []complex128) ([]float64, error) {
+	if err := checkLength("IFFTReal Input", len(x)); err != nil {
+		return nil, err
+	}
+	return ifftReal(x), nil
+}
+
+// FFTWithPower implements the fast Fourier transform, like FFT, and also returns
+// the power spectrum of the result, |X[k]|^2 for each bin, as PowerSpectrumPrecision
+// would, but filled during the final butterfly stage rather than in a second pass over x.
+// This is done in-place (modifying the input array).
+// The power spectrum is written to dst[:len(x)], which is returned, if
+// cap(dst) >= len(x), so passing the previous result back in as dst does no
+// allocations. Otherwise a new array is allocated, and dst may be nil.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func FFTWithPower(x []complex128, dst []float64) (power []float64, err error) {
+	if err := checkLength("FFTWithPower Input", len(x)); err != nil {
+		return nil, err
+	}
+	if cap(dst) >= len(x) {
+		power = dst[:len(x)]
+	} else {
+		power = make([]float64, len(x))
+	}
+	fftWithPower(x, power)
+	return power, nil
+}
+
+// FFTNoPermute implements the fast Fourier transform, like FFT, but skips the
+// bit-reversal permutation, leaving the output in bit-reversed order:
+// bin k is stored at the index with the bits of k reversed.
+// This is useful when the spectrum is only multiplied pointwise and passed to
+// IFFTFromBitReversed, as in a convolution, where the order doesn't matter.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func FFTNoPermute(x []complex128) error {
+	if err := checkLength("FFTNoPermute Input", len(x)); err != nil {
+		return err
+	}
+	fftNoPermute(x)
+	return nil
+}
+
+// IFFTFromBitReversed implements the inverse fast Fourier transform of
+// a spectrum in bit-reversed order, such as the output of FFTNoPermute,
+// skipping the bit-reversal permutation. The output is in natural order.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IFFTFromBitReversed(x []complex128) error {
+	if err := checkLength("IFFTFromBitReversed Input", len(x)); err != nil {
+		return err
+	}
+	ifftFromBitReversed(x)
+	return nil
+}
+
+// FFTStride implements the fast Fourier transform on the elements
+// x[0], x[stride], x[2*stride], ... of x, such as one channel of an interleaved buffer.
+// This is done in-place (modifying only those elements of the input array).
+// Requires O(1) additional memory.
+// stride must be positive, and the number of elements transformed,
+// ceil(len(x)/stride), must be a perfect power of 2, otherwise this will return an error.
+func FFTStride(x []complex128, stride int) error {
+	if err := checkPositive("FFTStride stride", stride); err != nil {
+		return err
+	}
+	N := (len(x) + stride - 1) / stride
+	if err := checkLength("FFTStride Input", N); err != nil {
+		return err
+	}
+	if stride == 1 {
+		fft(x)
+		return nil
+	}
+	fftStride(x, N, stride)
+	return nil
+}
+
+// TwiddleFactors returns the n-th roots of unity exp(-2*Pi*i*k/n) for k in [0, n),
+// each computed directly with math.Sincos, for use in custom butterflies.
+// Returns nil if n is not positive.
+func TwiddleFactors(n int) []complex128 {
+	if n <= 0 {
+		return nil
+	}
+	w := make([]complex128, n)
+	for k := range w {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		w[k] = complex(c, s)
+	}
+	return w
+}
+
+// Permutation returns the bit-reversal permutation that FFT applies to a vector
+// of length N, as a new array p where x[i] and x[p[i]] are swapped, so p[p[i]] == i.
+// FFTNoPermute leaves bin k at index p[k].
+// This is computed on each call, as nothing is cached.
+// N must be a perfect power of 2, otherwise this will return an error.
+func Permutation(N int) ([]int, error) {
+	if err := checkLength("Permutation length", N); err != nil {
+		return nil, err
+	}
+	p := make([]int, N)
+	shift := permutationShift(N)
+	for i := range p {
+		p[i] = permutationIndex(i, shift)
+	}
+	return p, nil
+}
+
 // fft does the actual work for FFT
 func fft(x []complex128) {
+	fftGeneric(x)
+}
+
+// fftWithPower does the actual work for FFTWithPower.
+// In bit-reversed order, the 4 quarters of x are the transforms of the inputs
+// congruent to 0, 2, 1 and 3 mod 4, so each is transformed on its own, and they are
+// combined by a final radix-4 stage that fills power as it writes each output.
+func fftWithPower(x []complex128, power []float64) {
 	N := len(x)
-	// Handle small N quickly
-	switch N {
-	case 1:
-		return
-	case 2:
-		x[0], x[1] = x[0]+x[1], x[0]-x[1]
-		return
-	case 4:
-		f := complex(imag(x[1])-imag(x[3]), real(x[3])-real(x[1]))
-		x[0], x[1], x[2], x[3] = x[0]+x[1]+x[2]+x[3], x[0]-x[2]+f, x[0]-x[1]+x[2]-x[3], x[0]-x[2]-f
+	if N < 16 {
+		fft(x)
+		for i, v := range x {
+			power[i] = real(v)*real(v) + imag(v)*imag(v)
+		}
 		return
 	}
-	// Reorder the input array.
 	permute(x)
-	// Butterfly
-	// First 2 steps
-	for i := 0; i < N; i += 4 {
-		f := complex(imag(x[i+2])-imag(x[i+3]), real(x[i+3])-real(x[i+2]))
-		x[i], x[i+1], x[i+2], x[i+3] = x[i]+x[i+1]+x[i+2]+x[i+3], x[i]-x[i+1]+f, x[i]-x[i+2]+x[i+1]-x[i+3], x[i]-x[i+1]-f
-	}
-	// Remaining steps
-	w := complex(0, -1)
-	for n := 4; n < N; n <<= 1 {
-		w = cmplx.Sqrt(w)
-		for o := 0; o < N; o += (n << 1) {
-			wj := complex(1, 0)
-			for k := 0; k < n; k++ {
-				i := k + o
-				f := wj * x[i+n]
-				x[i], x[i+n] = x[i]+f, x[i]-f
-				wj *= w
-			}
-		}
+	n := N / 4
+	for o := 0; o < N; o += n {
+		butterflyGeneric(x[o : o+n])
+	}
+	// The final stage is as in radix4Generic, with w = exp(-2πi/N)
+	s, c := math.Sincos(-2 * math.Pi / float64(N))
+	w := complex(c, s)
+	w2 := w * w
+	w3 := w2 * w
+	wj1, wj2, wj3 := complex(1, 0), complex(1, 0), complex(1, 0)
+	for k := 0; k < n; k++ {
+		t0 := x[k]
+		t1 := wj1 * x[k+2*n]
+		t2 := wj2 * x[k+n]
+		t3 := wj3 * x[k+3*n]
+		s0, s1 := t0+t2, t0-t2
+		s2, s3 := t1+t3, t1-t3
+		// Rotate s3 by -i
+		s3 = complex(imag(s3), -real(s3))
+		y0, y1, y2, y3 := s0+s2, s1+s3, s0-s2, s1-s3
+		x[k], x[k+n], x[k+2*n], x[k+3*n] = y0, y1, y2, y3
+		power[k] = real(y0)*real(y0) + imag(y0)*imag(y0)
+		power[k+n] = real(y1)*real(y1) + imag(y1)*imag(y1)
+		power[k+2*n] = real(y2)*real(y2) + imag(y2)*imag(y2)
+		power[k+3*n] = real(y3)*real(y3) + imag(y3)*imag(y3)
+		wj1 *= w
+		wj2 *= w2
+		wj3 *= w3
 	}
 }
 
 // ifft does the actual work for IFFT
 func ifft(x []complex128) {
+	ifftGeneric(x)
+}
+
+// ifftReal does the actual work for IFFTReal
+func ifftReal(x []complex128) []float64 {
 	N := len(x)
 	// Reverse the input vector
 	for i := 1; i < N/2; i++ {
@@ -107,76 +284,44 @@ func ifft(x []complex128) {
 	// Do the transform.
 	fft(x)
 
-	// Scale the output by 1/N
-	invN := complex(1.0/float64(N), 0)
-	for i := 0; i < N; i++ {
-		x[i] *= invN
+	// Scale the real parts of the output by 1/N
+	y := make([]float64, N)
+	invN := 1.0 / float64(N)
+	for i, v := range x {
+		y[i] = real(v) * invN
 	}
+	return y
 }
 
-// permutate permutes the input vector using bit reversal.
-// Uses an in-place algorithm that runs in O(N) time and O(1) additional space.
-func permute(x []complex128) {
+// fftNoPermute does the actual work for FFTNoPermute, with decimation-in-frequency
+// butterflies, which take natural order input to bit-reversed order output.
+func fftNoPermute(x []complex128) {
 	N := len(x)
-	// Handle small N quickly
-	switch N {
-	case 1, 2:
-		return
-	case 4:
-		x[1], x[2] = x[2], x[1]
-		return
-	case 8:
-		x[1], x[4] = x[4], x[1]
-		x[3], x[6] = x[6], x[3]
-		return
-	}
-	shift := 64 - uint64(bits.Len64(uint64(N-1)))
-	N2 := N >> 1
-	for i := 0; i < N; i += 2 {
-		ind := int(bits.Reverse64(uint64(i)) >> shift)
-		// Skip cases where low bit isn't set while high bit is
-		// This eliminates 25% of iterations
-		if i < N2 {
-			if ind > i {
-				x[i], x[ind] = x[ind], x[i]
+	for n := N >> 1; n > 0; n >>= 1 {
+		s, c := math.Sincos(-math.Pi / float64(n))
+		w := complex(c, s)
+		for o := 0; o < N; o += (n << 1) {
+			wj := complex(1, 0)
+			for k := 0; k < n; k++ {
+				i := k + o
+				a, b := x[i], x[i+n]
+				x[i], x[i+n] = a+b, (a-b)*wj
+				wj *= w
 			}
 		}
-		ind |= N2 // Fast way to get int(bits.Reverse64(uint64(i+1)) >> shift) here
-		if ind > i+1 {
-			x[i+1], x[ind] = x[ind], x[i+1]
-		}
 	}
 }
 
-// fft does the actual work for FFT
-func fft64(x []complex64) {
+// ifftFromBitReversed does the actual work for IFFTFromBitReversed, with
+// decimation-in-time butterflies, which take bit-reversed order input to
+// natural order output.
+func ifftFromBitReversed(x []complex128) {
 	N := len(x)
-	// Handle small N quickly
-	switch N {
-	case 1:
-		return
-	case 2:
-		x[0], x[1] = x[0]+x[1], x[0]-x[1]
-		return
-	case 4:
-		f := complex(imag(x[1])-imag(x[3]), real(x[3])-real(x[1]))
-		x[0], x[1], x[2], x[3] = x[0]+x[1]+x[2]+x[3], x[0]-x[2]+f, x[0]-x[1]+x[2]-x[3], x[0]-x[2]-f
-		return
-	}
-	// Reorder the input array.
-	permute64(x)
-	// Butterfly
-	// First 2 steps
-	for i := 0; i < N; i += 4 {
-		f := complex(float32(imag(x[i+2])-imag(x[i+3])), float32(real(x[i+3])-real(x[i+2])))
-		x[i], x[i+1], x[i+2], x[i+3] = x[i]+x[i+1]+x[i+2]+x[i+3], x[i]-x[i+1]+f, x[i]-x[i+2]+x[i+1]-x[i+3], x[i]-x[i+1]-f
-	}
-	// Remaining steps
-	w := complex(float32(0), float32(-1))
-	for n := 4; n < N; n <<= 1 {
-		w = complex64(cmplx.Sqrt(complex128(w)))
+	for n := 1; n < N; n <<= 1 {
+		s, c := math.Sincos(math.Pi / float64(n))
+		w := complex(c, s)
 		for o := 0; o < N; o += (n << 1) {
-			wj := complex(float32(1), float32(0))
+			wj := complex(1, 0)
 			for k := 0; k < n; k++ {
 				i := k + o
 				f := wj * x[i+n]
@@ -185,38 +330,54 @@ func fft64(x []complex64) {
 			}
 		}
 	}
+	// Scale the output by 1/N
+	invN := complex(1.0/float64(N), 0)
+	for i := 0; i < N; i++ {
+		x[i] *= invN
+	}
 }
 
-// permutate permutes the input vector using bit reversal.
-// Uses an in-place algorithm that runs in O(N) time and O(1) additional space.
-func permute64(x []complex64) {
-	N := len(x)
-	// Handle small N quickly
-	switch N {
-	case 1, 2:
-		return
-	case 4:
-		x[1], x[2] = x[2], x[1]
-		return
-	case 8:
-		x[1], x[4] = x[4], x[1]
-		x[3], x[6] = x[6], x[3]
+// fftStride does the actual work for FFTStride on the N elements x[0], x[stride], ...
+func fftStride(x []complex128, N, stride int) {
+	if N == 1 {
 		return
 	}
+	// Reorder the input elements.
 	shift := 64 - uint64(bits.Len64(uint64(N-1)))
-	N2 := N >> 1
-	for i := 0; i < N; i += 2 {
+	for i := 0; i < N; i++ {
 		ind := int(bits.Reverse64(uint64(i)) >> shift)
-		// Skip cases where low bit isn't set while high bit is
-		// This eliminates 25% of iterations
-		if i < N2 {
-			if ind > i {
-				x[i], x[ind] = x[ind], x[i]
-			}
+		if ind > i {
+			x[i*stride], x[ind*stride] = x[ind*stride], x[i*stride]
 		}
-		ind |= N2 // Fast way to get int(bits.Reverse64(uint64(i+1)) >> shift) here
-		if ind > i+1 {
-			x[i+1], x[ind] = x[ind], x[i+1]
+	}
+	// Butterfly
+	for n := 1; n < N; n <<= 1 {
+		s, c := math.Sincos(-math.Pi / float64(n))
+		w := complex(c, s)
+		for o := 0; o < N; o += (n << 1) {
+			wj := complex(1, 0)
+			for k := 0; k < n; k++ {
+				i, j := (k+o)*stride, (k+o+n)*stride
+				f := wj * x[j]
+				x[i], x[j] = x[i]+f, x[i]-f
+				wj *= w
+			}
 		}
 	}
 }
+
+// permute permutes the input vector using bit reversal.
+// Uses an in-place algorithm that runs in O(N) time and O(1) additional space.
+func permute(x []complex128) {
+	permuteGeneric(x)
+}
+
+// fft64 does the actual work for FFT32
+func fft64(x []complex64) {
+	fftGeneric(x)
+}
+
+// ifft64 does the actual work for IFFT32
+func ifft64(x []complex64) {
+	ifftGeneric(x)
+}
@@ -0,0 +1,233 @@
+package fft
+
+import "fmt"
+
+// FFT2D implements the 2-dimensional fast Fourier transform.
+// x is treated as a row-major rows×cols matrix, and is transformed
+// along each row and then along each column.
+// This is done in-place (modifying the input array).
+// Requires O(rows) additional memory, or O(1) if rows == cols.
+// rows and cols must be perfect powers of 2, and len(x) must equal rows*cols,
+// otherwise this will return an error.
+func FFT2D(x []complex128, rows, cols int) error {
+	if err := check2D("FFT2D", x, rows, cols); err != nil {
+		return err
+	}
+	fft2d(x, rows, cols, fft)
+	return nil
+}
+
+// IFFT2D implements the 2-dimensional inverse fast Fourier transform.
+// x is treated as a row-major rows×cols matrix, and is transformed
+// along each row and then along each column.
+// This is done in-place (modifying the input array).
+// Requires O(rows) additional memory, or O(1) if rows == cols.
+// rows and cols must be perfect powers of 2, and len(x) must equal rows*cols,
+// otherwise this will return an error.
+func IFFT2D(x []complex128, rows, cols int) error {
+	if err := check2D("IFFT2D", x, rows, cols); err != nil {
+		return err
+	}
+	fft2d(x, rows, cols, ifft)
+	return nil
+}
+
+// check2D checks that rows and cols are valid powers of 2 whose product matches len(x)
+func check2D(Context string, x []complex128, rows, cols int) error {
+	if err := checkLength(Context+" rows", rows); err != nil {
+		return err
+	}
+	if err := checkLength(Context+" cols", cols); err != nil {
+		return err
+	}
+	if err := checkProduct(Context, len(x), rows, cols); err != nil {
+		return err
+	}
+	return checkZero("difference in "+Context+" input length and rows*cols", len(x)-rows*cols)
+}
+
+// checkProduct checks that a*b, for positive a and b, doesn't overflow past MaxLength,
+// as then it can't be the length of any input
+func checkProduct(Context string, length, a, b int) error {
+	if a > MaxLength/b {
+		return &InputSizeError{Context: Context + " input length", Requirement: fmt.Sprintf("the product of the dimensions, which overflows past %d", MaxLength), Size: length, Err: ErrLengthMismatch}
+	}
+	return nil
+}
+
+// fft2d does the actual work for FFT2D and IFFT2D, applying the 1D transform
+// f across each row, and then across each column, by transposing square matrices,
+// or via a scratch buffer otherwise.
+func fft2d(x []complex128, rows, cols int, f func([]complex128)) {
+	for r := 0; r < rows; r++ {
+		f(x[r*cols : (r+1)*cols])
+	}
+	if rows == cols {
+		transposeSquare(x, rows)
+		for r := 0; r < rows; r++ {
+			f(x[r*cols : (r+1)*cols])
+		}
+		transposeSquare(x, rows)
+		return
+	}
+	col := make([]complex128, rows)
+	for c := 0; c < cols; c++ {
+		for r := 0; r < rows; r++ {
+			col[r] = x[r*cols+c]
+		}
+		f(col)
+		for r := 0; r < rows; r++ {
+			x[r*cols+c] = col[r]
+		}
+	}
+}
+
+// FFTN implements the N-dimensional fast Fourier transform.
+// x is treated as a row-major tensor of the given shape, and is transformed
+// along each axis in turn.
+// This is done in-place (modifying the input array).
+// Requires O(max(shape)) additional memory.
+// Each entry of shape must be a perfect power of 2, and len(x) must equal the
+// product of shape, otherwise this will return an error.
+func FFTN(x []complex128, shape []int) error {
+	if err := checkN("FFTN", x, shape); err != nil {
+		return err
+	}
+	fftn(x, shape, fft)
+	return nil
+}
+
+// IFFTN implements the N-dimensional inverse fast Fourier transform.
+// x is treated as a row-major tensor of the given shape, and is transformed
+// along each axis in turn.
+// This is done in-place (modifying the input array).
+// Requires O(max(shape)) additional memory.
+// Each entry of shape must be a perfect power of 2, and len(x) must equal the
+// product of shape, otherwise this will return an error.
+func IFFTN(x []complex128, shape []int) error {
+	if err := checkN("IFFTN", x, shape); err != nil {
+		return err
+	}
+	fftn(x, shape, ifft)
+	return nil
+}
+
+// checkN checks that each entry of shape is a valid power of 2, and that their product
+// matches len(x), without overflowing
+func checkN(Context string, x []complex128, shape []int) error {
+	size := 1
+	for i, d := range shape {
+		if err := checkLength(fmt.Sprintf("%s shape[%d]", Context, i), d); err != nil {
+			return err
+		}
+		if err := checkProduct(Context, len(x), size, d); err != nil {
+			return err
+		}
+		size *= d
+	}
+	return checkZero("difference in "+Context+" input length and product of shape", len(x)-size)
+}
+
+// fftn does the actual work for FFTN and IFFTN, applying the 1D transform f
+// along each axis, directly along the contiguous last axis, or via a scratch
+// buffer otherwise.
+func fftn(x []complex128, shape []int, f func([]complex128)) {
+	stride := len(x)
+	var buf []complex128
+	for _, d := range shape {
+		// stride is the distance between consecutive entries along this axis
+		block := stride
+		stride /= d
+		if stride == 1 {
+			for o := 0; o < len(x); o += d {
+				f(x[o : o+d])
+			}
+			continue
+		}
+		if len(buf) < d {
+			buf = make([]complex128, d)
+		}
+		line := buf[:d]
+		for o := 0; o < len(x); o += block {
+			for j := o; j < o+stride; j++ {
+				for i := range line {
+					line[i] = x[j+i*stride]
+				}
+				f(line)
+				for i, v := range line {
+					x[j+i*stride] = v
+				}
+			}
+		}
+	}
+}
+
+// transposeBlock is the side length of the blocks used to transpose matrices,
+// chosen so that a pair of blocks fits comfortably in the L1 cache.
+const transposeBlock = 32
+
+// TransposeSquare transposes x, a row-major n×n matrix, in-place.
+// Works on square blocks at a time for cache efficiency.
+// Requires O(1) additional memory.
+// n must be positive, and len(x) must equal n*n, otherwise this will return an error.
+func TransposeSquare(x []complex128, n int) error {
+	if err := checkPositive("TransposeSquare n", n); err != nil {
+		return err
+	}
+	if err := checkProduct("TransposeSquare", len(x), n, n); err != nil {
+		return err
+	}
+	if err := checkZero("difference in TransposeSquare input length and n*n", len(x)-n*n); err != nil {
+		return err
+	}
+	transposeSquare(x, n)
+	return nil
+}
+
+// TransposeRect transposes x, a row-major rows×cols matrix, into a new
+// row-major cols×rows matrix. This does not alter x.
+// Works on square blocks at a time for cache efficiency.
+// rows and cols must be positive, and len(x) must equal rows*cols, otherwise this will return an error.
+func TransposeRect(x []complex128, rows, cols int) ([]complex128, error) {
+	if err := checkPositive("TransposeRect rows", rows); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("TransposeRect cols", cols); err != nil {
+		return nil, err
+	}
+	if err := checkProduct("TransposeRect", len(x), rows, cols); err != nil {
+		return nil, err
+	}
+	if err := checkZero("difference in TransposeRect input length and rows*cols", len(x)-rows*cols); err != nil {
+		return nil, err
+	}
+	y := make([]complex128, len(x))
+	for ib := 0; ib < rows; ib += transposeBlock {
+		for jb := 0; jb < cols; jb += transposeBlock {
+			for i := ib; i < min(ib+transposeBlock, rows); i++ {
+				for j := jb; j < min(jb+transposeBlock, cols); j++ {
+					y[j*rows+i] = x[i*cols+j]
+				}
+			}
+		}
+	}
+	return y, nil
+}
+
+// transposeSquare does the actual work for TransposeSquare, swapping each
+// block above the diagonal with its mirror below.
+func transposeSquare(x []complex128, n int) {
+	for ib := 0; ib < n; ib += transposeBlock {
+		for jb := ib; jb < n; jb += transposeBlock {
+			for i := ib; i < min(ib+transposeBlock, n); i++ {
+				j0 := jb
+				if ib == jb {
+					j0 = i + 1
+				}
+				for j := j0; j < min(jb+transposeBlock, n); j++ {
+					x[i*n+j], x[j*n+i] = x[j*n+i], x[i*n+j]
+				}
+			}
+		}
+	}
+}
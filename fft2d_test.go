@@ -0,0 +1,241 @@
+package fft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func slowConvolve2D(x []complex128, xr, xc int, y []complex128, yr, yc int) []complex128 {
+	rows, cols := xr+yr-1, xc+yc-1
+	r := make([]complex128, rows*cols)
+	for i := 0; i < xr; i++ {
+		for j := 0; j < xc; j++ {
+			for k := 0; k < yr; k++ {
+				for l := 0; l < yc; l++ {
+					r[(i+k)*cols+j+l] += x[i*xc+j] * y[k*yc+l]
+				}
+			}
+		}
+	}
+	return r
+}
+
+// pad2D copies the rows×cols matrix x into the top-left of a new R×C matrix
+func pad2D(x []complex128, rows, cols, R, C int) []complex128 {
+	y := make([]complex128, R*C)
+	for i := 0; i < rows; i++ {
+		copy(y[i*C:], x[i*cols:(i+1)*cols])
+	}
+	return y
+}
+
+func TestFFT2D(t *testing.T) {
+	// Test FFT2D of non-powers of 2 returns InputSizeError
+	checkIsInputSizeError(t, "FFT2D(complexRand(12), 3, 4)", FFT2D(complexRand(12), 3, 4))
+	checkIsInputSizeError(t, "FFT2D(complexRand(12), 4, 3)", FFT2D(complexRand(12), 4, 3))
+	checkIsInputSizeError(t, "FFT2D(complexRand(8), 4, 4)", FFT2D(complexRand(8), 4, 4))
+	checkIsInputSizeError(t, "IFFT2D(complexRand(12), 3, 4)", IFFT2D(complexRand(12), 3, 4))
+	// Test rows*cols overflowing to 0 is rejected, rather than matching an empty x
+	err := FFT2D(nil, MaxLength, 4)
+	checkIsInputSizeError(t, "FFT2D(nil, MaxLength, 4)", err)
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("FFT2D(nil, MaxLength, 4), got: %v, expected: ErrLengthMismatch", err)
+	}
+	checkIsInputSizeError(t, "IFFT2D(nil, 4, MaxLength)", IFFT2D(nil, 4, MaxLength))
+	// Test IFFT2D(FFT2D(x)) == x
+	for rows := 1; rows <= 64; rows <<= 1 {
+		for cols := 1; cols <= 64; cols <<= 1 {
+			x := complexRand(rows * cols)
+			y := copyVector(x)
+			if err := FFT2D(y, rows, cols); err != nil {
+				t.Errorf("FFT2D error: %v", err)
+			}
+			if err := IFFT2D(y, rows, cols); err != nil {
+				t.Errorf("IFFT2D error: %v", err)
+			}
+			for i := range x {
+				if e := cmplx.Abs(x[i] - y[i]); e > 1e-9 {
+					t.Errorf("inverse differs rows=%d cols=%d %d: %v %v", rows, cols, i, x[i], y[i])
+				}
+			}
+		}
+	}
+	// Test 2D convolution via FFT2D matches slowConvolve2D
+	for _, s := range [][4]int{{1, 1, 1, 1}, {2, 3, 3, 2}, {5, 4, 3, 3}, {7, 2, 4, 6}} {
+		xr, xc, yr, yc := s[0], s[1], s[2], s[3]
+		x := complexRand(xr * xc)
+		y := complexRand(yr * yc)
+		r1 := slowConvolve2D(x, xr, xc, y, yr, yc)
+		rows, cols := xr+yr-1, xc+yc-1
+		R, C := NextPow2(rows), NextPow2(cols)
+		px := pad2D(x, xr, xc, R, C)
+		py := pad2D(y, yr, yc, R, C)
+		if err := FFT2D(px, R, C); err != nil {
+			t.Errorf("FFT2D error: %v", err)
+		}
+		if err := FFT2D(py, R, C); err != nil {
+			t.Errorf("FFT2D error: %v", err)
+		}
+		for i := range px {
+			px[i] *= py[i]
+		}
+		if err := IFFT2D(px, R, C); err != nil {
+			t.Errorf("IFFT2D error: %v", err)
+		}
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				if e := cmplx.Abs(r1[i*cols+j] - px[i*C+j]); e > 1e-9 {
+					t.Errorf("slowConvolve2D and FFT2D convolution differ at (%d, %d): %v %v, diff=%v", i, j, r1[i*cols+j], px[i*C+j], e)
+				}
+			}
+		}
+	}
+}
+
+// slowDFTN computes the N-dimensional DFT of the row-major tensor x directly
+func slowDFTN(x []complex128, shape []int) []complex128 {
+	y := make([]complex128, len(x))
+	idx := func(flat int) []int {
+		id := make([]int, len(shape))
+		for a := len(shape) - 1; a >= 0; a-- {
+			id[a] = flat % shape[a]
+			flat /= shape[a]
+		}
+		return id
+	}
+	for k := range y {
+		kid := idx(k)
+		for n, v := range x {
+			nid := idx(n)
+			phase := 0.0
+			for a := range shape {
+				phase += float64(kid[a]*nid[a]) / float64(shape[a])
+			}
+			y[k] += v * cmplx.Exp(complex(0, -2*math.Pi*phase))
+		}
+	}
+	return y
+}
+
+func TestFFTN(t *testing.T) {
+	// Test invalid shapes return InputSizeError
+	checkIsInputSizeError(t, "FFTN(complexRand(24), []int{2, 3, 4})", FFTN(complexRand(24), []int{2, 3, 4}))
+	checkIsInputSizeError(t, "FFTN(complexRand(16), []int{2, 4})", FFTN(complexRand(16), []int{2, 4}))
+	checkIsInputSizeError(t, "IFFTN(complexRand(24), []int{2, 3, 4})", IFFTN(complexRand(24), []int{2, 3, 4}))
+	// Test a shape whose product overflows to 0 is rejected, rather than matching an empty x
+	err := FFTN(nil, []int{MaxLength, 4})
+	checkIsInputSizeError(t, "FFTN(nil, []int{MaxLength, 4})", err)
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("FFTN(nil, []int{MaxLength, 4}), got: %v, expected: ErrLengthMismatch", err)
+	}
+	checkIsInputSizeError(t, "IFFTN(complexRand(4), []int{2, MaxLength, 2, 2})", IFFTN(complexRand(4), []int{2, MaxLength, 2, 2}))
+	for _, shape := range [][]int{{}, {8}, {4, 8}, {2, 4, 8}, {4, 1, 2}, {2, 2, 2, 4}} {
+		size := 1
+		for _, d := range shape {
+			size *= d
+		}
+		x := complexRand(size)
+		// Test FFTN(x) == slowDFTN(x)
+		y1 := slowDFTN(x, shape)
+		y2 := copyVector(x)
+		if err := FFTN(y2, shape); err != nil {
+			t.Errorf("FFTN error: %v", err)
+		}
+		for i := range y1 {
+			if e := cmplx.Abs(y1[i] - y2[i]); e > 1e-9 {
+				t.Errorf("slowDFTN and FFTN differ: shape=%v i=%d y1=%v y2=%v diff=%v", shape, i, y1[i], y2[i], e)
+			}
+		}
+		// Test IFFTN(FFTN(x)) == x
+		if err := IFFTN(y2, shape); err != nil {
+			t.Errorf("IFFTN error: %v", err)
+		}
+		for i := range x {
+			if e := cmplx.Abs(x[i] - y2[i]); e > 1e-9 {
+				t.Errorf("IFFTN(FFTN(x)) differs: shape=%v i=%d got=%v expected=%v diff=%v", shape, i, y2[i], x[i], e)
+			}
+		}
+	}
+	// Test FFTN of a 2D shape matches FFT2D
+	x := complexRand(8 * 16)
+	y1, y2 := copyVector(x), copyVector(x)
+	FFT2D(y1, 8, 16)
+	FFTN(y2, []int{8, 16})
+	for i := range y1 {
+		if e := cmplx.Abs(y1[i] - y2[i]); e > 1e-9 {
+			t.Errorf("FFT2D and FFTN differ: i=%d y1=%v y2=%v diff=%v", i, y1[i], y2[i], e)
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	// Test mismatched lengths return InputSizeError
+	checkIsInputSizeError(t, "TransposeSquare(complexRand(10), 3)", TransposeSquare(complexRand(10), 3))
+	_, err := TransposeRect(complexRand(10), 3, 4)
+	checkIsInputSizeError(t, "TransposeRect(complexRand(10), 3, 4)", err)
+	// Test non-positive dimensions return InputSizeError, even when their product matches len(x)
+	checkIsInputSizeError(t, "TransposeSquare(complexRand(1), -1)", TransposeSquare(complexRand(1), -1))
+	checkIsInputSizeError(t, "TransposeSquare(nil, 0)", TransposeSquare(nil, 0))
+	_, err = TransposeRect(complexRand(6), -2, -3)
+	checkIsInputSizeError(t, "TransposeRect(complexRand(6), -2, -3)", err)
+	_, err = TransposeRect(nil, 0, 4)
+	checkIsInputSizeError(t, "TransposeRect(nil, 0, 4)", err)
+	_, err = TransposeRect(nil, 4, 0)
+	checkIsInputSizeError(t, "TransposeRect(nil, 4, 0)", err)
+	// Test n*n and rows*cols overflowing to 0 is rejected, rather than matching an empty x
+	err = TransposeSquare(nil, MaxLength)
+	checkIsInputSizeError(t, "TransposeSquare(nil, MaxLength)", err)
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("TransposeSquare(nil, MaxLength), got: %v, expected: ErrLengthMismatch", err)
+	}
+	_, err = TransposeRect(nil, MaxLength, 4)
+	checkIsInputSizeError(t, "TransposeRect(nil, MaxLength, 4)", err)
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("TransposeRect(nil, MaxLength, 4), got: %v, expected: ErrLengthMismatch", err)
+	}
+	// Test TransposeSquare and TransposeRect on sizes around the block size
+	for _, rows := range []int{1, 5, 31, 32, 33, 70} {
+		x := complexRand(rows * rows)
+		y := copyVector(x)
+		if err := TransposeSquare(y, rows); err != nil {
+			t.Errorf("TransposeSquare error: %v", err)
+		}
+		for i := 0; i < rows; i++ {
+			for j := 0; j < rows; j++ {
+				if y[j*rows+i] != x[i*rows+j] {
+					t.Errorf("TransposeSquare(%d) differs at (%d, %d)", rows, i, j)
+				}
+			}
+		}
+		for _, cols := range []int{1, 7, 32, 65} {
+			x := complexRand(rows * cols)
+			y, err := TransposeRect(x, rows, cols)
+			if err != nil {
+				t.Errorf("TransposeRect error: %v", err)
+			}
+			for i := 0; i < rows; i++ {
+				for j := 0; j < cols; j++ {
+					if y[j*rows+i] != x[i*cols+j] {
+						t.Errorf("TransposeRect(%d, %d) differs at (%d, %d)", rows, cols, i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkFFT2D(b *testing.B) {
+	for _, n := range []int{64, 512, 2048} {
+		x := complexRand(n * n)
+		b.Run(fmt.Sprintf("%dx%d", n, n), func(b *testing.B) {
+			b.SetBytes(int64(n * n * 16))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				FFT2D(x, n, n)
+			}
+		})
+	}
+}
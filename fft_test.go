@@ -1,11 +1,13 @@
 package fft
 
 import (
+	"errors"
 	"math"
 	"math/bits"
 	"math/cmplx"
 	"math/rand"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 
@@ -60,6 +62,17 @@ func TestPrepare(t *testing.T) {
 			t.Errorf("Prepare error on power of 2: %v", err)
 		}
 	}
+	// Test Prepare accepts MaxLength, but not the overflowed power of 2 above it
+	if err := Prepare(MaxLength); err != nil {
+		t.Errorf("Prepare(MaxLength) error: %v", err)
+	}
+	overflowed := MaxLength
+	overflowed <<= 1
+	err := Prepare(overflowed)
+	checkIsInputSizeError(t, "Prepare(MaxLength << 1)", err)
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Prepare(MaxLength << 1) error, got: %v, expected to wrap ErrOutOfRange", err)
+	}
 }
 
 func TestFFT(t *testing.T) {
@@ -83,6 +96,33 @@ func TestFFT(t *testing.T) {
 	}
 }
 
+func TestFFTConcurrent(t *testing.T) {
+	// FFT keeps no package-level tables, so concurrent first use from many
+	// goroutines must not depend on any initialization order
+	const goroutines = 16
+	var wg sync.WaitGroup
+	errs := make([]float64, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			x := complexRand(1 << (4 + g%6))
+			y1 := slowFFT(copyVector(x))
+			y2 := copyVector(x)
+			FFT(y2)
+			for i := range y1 {
+				errs[g] = math.Max(errs[g], cmplx.Abs(y1[i]-y2[i]))
+			}
+		}(g)
+	}
+	wg.Wait()
+	for g, e := range errs {
+		if e > 1e-9 {
+			t.Errorf("concurrent FFT differs from slowFFT: goroutine=%d diff=%v", g, e)
+		}
+	}
+}
+
 func TestIFFT(t *testing.T) {
 	// Test IFFT of non-powers of 2 returns InputSizeError
 	checkIsInputSizeError(t, "IFFT(complexRand(17))", IFFT(complexRand(17)))
@@ -106,6 +146,216 @@ func TestIFFT(t *testing.T) {
 	}
 }
 
+func TestIFFTReal(t *testing.T) {
+	// Test IFFTReal of non-powers of 2 returns InputSizeError
+	_, err := IFFTReal(complexRand(17))
+	checkIsInputSizeError(t, "IFFTReal(complexRand(17))", err)
+	// Test IFFTReal(FFT(x)) == x for real x
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N)
+		y := Float64ToComplex128Array(x)
+		FFT(y)
+		got, err := IFFTReal(y)
+		if err != nil {
+			t.Errorf("IFFTReal error: %v", err)
+		}
+		for i := range x {
+			if e := math.Abs(got[i] - x[i]); e > 1e-9 {
+				t.Errorf("IFFTReal(FFT(x)) differs: N=%d i=%d got=%v expected=%v diff=%v", N, i, got[i], x[i], e)
+			}
+		}
+	}
+}
+
+func TestFFTChecked(t *testing.T) {
+	// Test FFTChecked of non-powers of 2 returns InputSizeError
+	checkIsInputSizeError(t, "FFTChecked(complexRand(17))", FFTChecked(complexRand(17)))
+	// Test FFTChecked with a NaN returns an InvalidInputError at its index, leaving x unchanged
+	x := complexRand(16)
+	x[5] = complex(math.NaN(), 0)
+	y := copyVector(x)
+	err := FFTChecked(y)
+	if e, ok := err.(*InvalidInputError); !ok || e.Index != 5 {
+		t.Errorf("FFTChecked with NaN at index 5, got: %v, expected: *InvalidInputError at index 5", err)
+	}
+	for i := range x {
+		if i != 5 && x[i] != y[i] {
+			t.Errorf("FFTChecked with NaN modified the input: y[%d]=%v, expected: %v", i, y[i], x[i])
+		}
+	}
+	// Test FFTChecked(x) == FFT(x) for finite input
+	x = complexRand(64)
+	y = copyVector(x)
+	if err := FFTChecked(x); err != nil {
+		t.Errorf("FFTChecked error: %v", err)
+	}
+	FFT(y)
+	for i := range x {
+		if x[i] != y[i] {
+			t.Errorf("FFTChecked and FFT differ: x[%d]=%v, y[%d]=%v", i, x[i], i, y[i])
+		}
+	}
+}
+
+func TestFFT32(t *testing.T) {
+	// Test FFT32 and IFFT32 of non-powers of 2 returns InputSizeError
+	checkIsInputSizeError(t, "FFT32(make([]complex64, 17))", FFT32(make([]complex64, 17)))
+	checkIsInputSizeError(t, "IFFT32(make([]complex64, 17))", IFFT32(make([]complex64, 17)))
+	// Test FFT32(x) == slowFFT(x) and IFFT32(FFT32(x)) == x for power of 2 up to 2^10
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := Complex128ToComplex64(complexRand(N))
+		y1 := slowFFT(Complex64ToComplex128(x))
+		y2 := make([]complex64, N)
+		copy(y2, x)
+		if err := FFT32(y2); err != nil {
+			t.Errorf("FFT32 error: %v", err)
+		}
+		// Error grows with the magnitude of the output, which is on the order of sqrt(N)
+		errorThreshold := 1e-4 * math.Sqrt(float64(N))
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(y1[i] - complex128(y2[i])); e > errorThreshold {
+				t.Errorf("slowFFT and FFT32 differ: i=%d N=%d y1[%d]=%v y2[%d]=%v diff=%v", i, N, i, y1[i], i, y2[i], e)
+			}
+		}
+		if err := IFFT32(y2); err != nil {
+			t.Errorf("IFFT32 error: %v", err)
+		}
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(complex128(x[i]) - complex128(y2[i])); e > 1e-4 {
+				t.Errorf("IFFT32 inverse differs %d: %v %v", i, x[i], y2[i])
+			}
+		}
+	}
+}
+
+func TestFFTWithPower(t *testing.T) {
+	_, err := FFTWithPower(complexRand(17), nil)
+	checkIsInputSizeError(t, "FFTWithPower(complexRand(17), nil)", err)
+	// Test FFTWithPower matches FFT and PowerSpectrumPrecision, for power of 2 up to 2^12
+	for N := 1; N < (1 << 13); N <<= 1 {
+		x := complexRand(N)
+		y1 := copyVector(x)
+		FFT(y1)
+		p1 := PowerSpectrumPrecision(y1)
+		y2 := copyVector(x)
+		p2, err := FFTWithPower(y2, nil)
+		if err != nil {
+			t.Errorf("FFTWithPower error: %v", err)
+		}
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(y1[i] - y2[i]); e > 1e-9 {
+				t.Errorf("FFT and FFTWithPower differ: N=%d i=%d y1=%v y2=%v diff=%v", N, i, y1[i], y2[i], e)
+			}
+			// The power grows with N, so compare relative to it
+			if e := math.Abs(p1[i] - p2[i]); e > 1e-9*math.Max(1, p1[i]) {
+				t.Errorf("PowerSpectrumPrecision and FFTWithPower differ: N=%d i=%d p1=%v p2=%v diff=%v", N, i, p1[i], p2[i], e)
+			}
+		}
+	}
+	// Test dst is reused when it has the capacity, and resliced to len(x)
+	dst := make([]float64, 10, 64)
+	p, _ := FFTWithPower(complexRand(32), dst)
+	if len(p) != 32 || &p[0] != &dst[0] {
+		t.Errorf("FFTWithPower didn't reuse dst: len(p)=%d, len(dst)=%d, cap(dst)=%d", len(p), len(dst), cap(dst))
+	}
+	if p, _ := FFTWithPower(complexRand(128), dst); len(p) != 128 {
+		t.Errorf("FFTWithPower with a short dst length, got: %d, expected: 128", len(p))
+	}
+	for _, N := range []int{8, 1024} {
+		x := complexRand(N)
+		power, _ := FFTWithPower(x, nil)
+		if allocs := testing.AllocsPerRun(10, func() { power, _ = FFTWithPower(x, power) }); allocs != 0 {
+			t.Errorf("FFTWithPower(N=%d) reusing dst, got: %v allocations, expected: 0", N, allocs)
+		}
+	}
+}
+
+func TestFFTNoPermute(t *testing.T) {
+	// Test non-powers of 2 return InputSizeError
+	checkIsInputSizeError(t, "FFTNoPermute(complexRand(17))", FFTNoPermute(complexRand(17)))
+	checkIsInputSizeError(t, "IFFTFromBitReversed(complexRand(17))", IFFTFromBitReversed(complexRand(17)))
+	// Test FFTNoPermute(x) == slowFFT(x) in bit-reversed order, and IFFTFromBitReversed inverts it
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := complexRand(N)
+		y1 := slowFFT(copyVector(x))
+		y2 := copyVector(x)
+		if err := FFTNoPermute(y2); err != nil {
+			t.Errorf("FFTNoPermute error: %v", err)
+		}
+		shift := 64 - uint64(bits.Len64(uint64(N-1)))
+		for i := 0; i < N; i++ {
+			j := int(bits.Reverse64(uint64(i)) >> shift)
+			if e := cmplx.Abs(y1[i] - y2[j]); e > 1e-9 {
+				t.Errorf("slowFFT and FFTNoPermute differ: N=%d y1[%d]=%v y2[%d]=%v diff=%v", N, i, y1[i], j, y2[j], e)
+			}
+		}
+		if err := IFFTFromBitReversed(y2); err != nil {
+			t.Errorf("IFFTFromBitReversed error: %v", err)
+		}
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(x[i] - y2[i]); e > 1e-9 {
+				t.Errorf("IFFTFromBitReversed(FFTNoPermute(x)) differs from x: N=%d i=%d got=%v expected=%v diff=%v", N, i, y2[i], x[i], e)
+			}
+		}
+	}
+}
+
+func TestFFTStride(t *testing.T) {
+	// Test FFTStride of non-powers of 2 or non-positive strides returns InputSizeError
+	checkIsInputSizeError(t, "FFTStride(complexRand(34), 2)", FFTStride(complexRand(34), 2))
+	checkIsInputSizeError(t, "FFTStride(complexRand(16), 0)", FFTStride(complexRand(16), 0))
+	// Test FFTStride(x, stride) == slowFFT of the strided elements, leaving the others untouched
+	for stride := 1; stride < 5; stride++ {
+		for N := 1; N < (1 << 9); N <<= 1 {
+			// Test both exact multiples of the stride and a trailing partial stride
+			for _, extra := range []int{0, stride - 1} {
+				x := complexRand(N*stride - extra)
+				y := copyVector(x)
+				if err := FFTStride(y, stride); err != nil {
+					t.Errorf("FFTStride error: %v", err)
+					continue
+				}
+				sub := make([]complex128, N)
+				for i := range sub {
+					sub[i] = x[i*stride]
+				}
+				r := slowFFT(sub)
+				for i := range y {
+					expect := x[i]
+					if i%stride == 0 {
+						expect = r[i/stride]
+					}
+					if e := cmplx.Abs(expect - y[i]); e > 1e-9 {
+						t.Errorf("FFTStride differs: stride=%d N=%d y[%d]=%v expected=%v diff=%v", stride, N, i, y[i], expect, e)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTwiddleFactors(t *testing.T) {
+	if w := TwiddleFactors(0); w != nil {
+		t.Errorf("TwiddleFactors(0), got: %v, expected: nil", w)
+	}
+	for n := 1; n < 100; n++ {
+		w := TwiddleFactors(n)
+		if len(w) != n {
+			t.Errorf("TwiddleFactors(%d) length, got: %d, expected: %d", n, len(w), n)
+			continue
+		}
+		for k := range w {
+			// Test each factor is an n-th root of unity, and is the k-th power of the first
+			if e := cmplx.Abs(cmplx.Pow(w[k], complex(float64(n), 0)) - 1); e > 1e-9 {
+				t.Errorf("TwiddleFactors(%d)[%d]^n != 1, diff=%v", n, k, e)
+			}
+			if e := cmplx.Abs(w[k] - cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(n)))); e > 1e-15 {
+				t.Errorf("TwiddleFactors(%d)[%d], got: %v, expected: exp(-2*Pi*i*%d/%d), diff=%v", n, k, w[k], k, n, e)
+			}
+		}
+	}
+}
+
 func TestPermute(t *testing.T) {
 	shift := uint64(64)
 	for n := 1; n < (1 << 11); n <<= 1 {
@@ -123,6 +373,73 @@ func TestPermute(t *testing.T) {
 	}
 }
 
+func TestPermutation(t *testing.T) {
+	// Test non-powers of 2 return InputSizeError
+	_, err := Permutation(12)
+	checkIsInputSizeError(t, "Permutation(12)", err)
+	_, err = Permutation(0)
+	checkIsInputSizeError(t, "Permutation(0)", err)
+	for N := 1; N <= 1<<10; N <<= 1 {
+		p, err := Permutation(N)
+		if err != nil {
+			t.Fatalf("Permutation(%d) error: %v", N, err)
+		}
+		if len(p) != N {
+			t.Fatalf("Permutation(%d) length, got: %d, expected: %d", N, len(p), N)
+		}
+		// Test gathering x by p matches permute, and FFTNoPermute leaves bin k at p[k]
+		x := complexRand(N)
+		y := copyVector(x)
+		permute(y)
+		for i := range p {
+			if p[p[i]] != i {
+				t.Errorf("Permutation(%d) is not an involution: p[%d]=%d, p[%d]=%d", N, i, p[i], p[i], p[p[i]])
+			}
+			if y[i] != x[p[i]] {
+				t.Errorf("Permutation(%d) differs from permute at %d", N, i)
+			}
+		}
+		z1, z2 := copyVector(x), copyVector(x)
+		FFT(z1)
+		FFTNoPermute(z2)
+		for k := range p {
+			if e := cmplx.Abs(z1[k] - z2[p[k]]); e > 1e-9 {
+				t.Errorf("FFT and FFTNoPermute differ at Permutation(%d): z1[%d]=%v, z2[%d]=%v, diff=%v", N, k, z1[k], p[k], z2[p[k]], e)
+			}
+		}
+	}
+}
+
+func TestPermutationIndex(t *testing.T) {
+	// Test the index math for lengths far too large to allocate, up to MaxLength,
+	// against a bit by bit reversal
+	slowReverse := func(i, N int) int {
+		r := 0
+		for b := 1; b < N; b <<= 1 {
+			r <<= 1
+			if i&b != 0 {
+				r |= 1
+			}
+		}
+		return r
+	}
+	for N := 1; N > 0 && N <= MaxLength; N <<= 1 {
+		shift := permutationShift(N)
+		for _, i := range []int{0, 1, 2, 3, N / 2, N / 3, N - 2, N - 1} {
+			if i < 0 || i >= N {
+				continue
+			}
+			ind := permutationIndex(i, shift)
+			if expect := slowReverse(i, N); ind != expect {
+				t.Errorf("permutationIndex(%d) for N=%d, got: %d, expected: %d", i, N, ind, expect)
+			}
+			if ind < 0 || ind >= N || permutationIndex(ind, shift) != i {
+				t.Errorf("permutationIndex(%d) for N=%d is not an involution in [0, N): %d", i, N, ind)
+			}
+		}
+	}
+}
+
 var (
 	benchmarks = []struct {
 		size int
@@ -235,6 +552,21 @@ func BenchmarkFFT(b *testing.B) {
 	}
 }
 
+func BenchmarkFFTWithPower(b *testing.B) {
+	for _, bm := range benchmarks {
+		x := complexRand(bm.size)
+		var power []float64
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(bm.size * 16))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				power, _ = FFTWithPower(x, power)
+			}
+		})
+	}
+}
+
 func BenchmarkFFTParallel(b *testing.B) {
 	for _, bm := range benchmarks {
 		procs := runtime.GOMAXPROCS(0)
@@ -289,6 +621,20 @@ func BenchmarkIFFTParallel(b *testing.B) {
 	}
 }
 
+func BenchmarkFFT32(b *testing.B) {
+	for _, bm := range benchmarks {
+		x := Complex128ToComplex64(complexRand(bm.size))
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(bm.size * 8))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				FFT32(x)
+			}
+		})
+	}
+}
+
 func BenchmarkPermute(b *testing.B) {
 	for _, bm := range benchmarks {
 		x := complexRand(bm.size)
@@ -0,0 +1,41 @@
+// Package fftmat adapts the fast Fourier transforms of package fft to gonum's
+// complex matrices, transforming them in-place through their backing slices,
+// without copying them to and from []complex128.
+//
+// gonum has no dense complex vector type, so a complex vector is held as a
+// 1×n *mat.CDense, and transformed with FFTMatRows.
+package fftmat
+
+import (
+	"github.com/andewx/fft"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FFTMatRows implements the fast Fourier transform on each row of m.
+// This is done in-place (modifying m), through its RawCMatrix backing slice,
+// so it also works on views created by Slice.
+// Requires O(1) additional memory.
+// The number of columns of m must be a perfect power of 2, otherwise this will return an error.
+func FFTMatRows(m *mat.CDense) error {
+	return eachRow(m, fft.FFT)
+}
+
+// IFFTMatRows implements the inverse fast Fourier transform on each row of m.
+// This is done in-place (modifying m), through its RawCMatrix backing slice,
+// so it also works on views created by Slice.
+// Requires O(1) additional memory.
+// The number of columns of m must be a perfect power of 2, otherwise this will return an error.
+func IFFTMatRows(m *mat.CDense) error {
+	return eachRow(m, fft.IFFT)
+}
+
+// eachRow applies the transform f to each row of m, stopping at the first error
+func eachRow(m *mat.CDense, f func([]complex128) error) error {
+	raw := m.RawCMatrix()
+	for i := 0; i < raw.Rows; i++ {
+		if err := f(raw.Data[i*raw.Stride : i*raw.Stride+raw.Cols]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
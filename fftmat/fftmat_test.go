@@ -0,0 +1,63 @@
+package fftmat
+
+import (
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/andewx/fft"
+	"gonum.org/v1/gonum/mat"
+)
+
+func complexRand(N int) []complex128 {
+	x := make([]complex128, N)
+	for i := 0; i < N; i++ {
+		x[i] = complex(2.0*rand.Float64()-1.0, 2.0*rand.Float64()-1.0)
+	}
+	return x
+}
+
+func TestFFTMatRows(t *testing.T) {
+	// Test a non-power of 2 number of columns returns an InputSizeError
+	if _, ok := FFTMatRows(mat.NewCDense(2, 3, nil)).(*fft.InputSizeError); !ok {
+		t.Errorf("FFTMatRows of a 2×3 matrix didn't return an InputSizeError")
+	}
+	// Test FFTMatRows on a view transforms only its rows, matching fft.FFT
+	data := complexRand(6 * 10)
+	m := mat.NewCDense(6, 10, append([]complex128(nil), data...))
+	view := m.Slice(1, 5, 2, 10).(*mat.CDense)
+	if err := FFTMatRows(view); err != nil {
+		t.Errorf("FFTMatRows error: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		row := append([]complex128(nil), data[i*10:(i+1)*10]...)
+		if i >= 1 && i < 5 {
+			fft.FFT(row[2:])
+		}
+		for j := 0; j < 10; j++ {
+			if e := cmplx.Abs(m.At(i, j) - row[j]); e > 1e-9 {
+				t.Errorf("FFTMatRows differs at (%d, %d): got=%v expected=%v diff=%v", i, j, m.At(i, j), row[j], e)
+			}
+		}
+	}
+	// Test IFFTMatRows inverts FFTMatRows, and a 1×n vector is transformed like fft.FFT
+	if err := IFFTMatRows(view); err != nil {
+		t.Errorf("IFFTMatRows error: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 10; j++ {
+			if e := cmplx.Abs(m.At(i, j) - data[i*10+j]); e > 1e-9 {
+				t.Errorf("IFFTMatRows(FFTMatRows(m)) differs at (%d, %d): got=%v expected=%v diff=%v", i, j, m.At(i, j), data[i*10+j], e)
+			}
+		}
+	}
+	v := complexRand(16)
+	vec := mat.NewCDense(1, 16, append([]complex128(nil), v...))
+	FFTMatRows(vec)
+	fft.FFT(v)
+	for j := range v {
+		if e := cmplx.Abs(vec.At(0, j) - v[j]); e > 1e-9 {
+			t.Errorf("FFTMatRows of a vector differs at %d: got=%v expected=%v diff=%v", j, vec.At(0, j), v[j], e)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package fft
+
+import "math"
+
+// BandpassFFT filters the real signal x in the frequency domain, keeping only the
+// frequencies in [fLow, fHigh] Hz. x is transformed with FFT, the bins outside the
+// band (and their conjugate-symmetric mirrors) are zeroed, and the result is
+// transformed back with IFFT.
+// Zeroing bins is a circular convolution with a long, ringing kernel, so the
+// start and end of x bleed into each other, and transients ring around the
+// band edges. Use BandpassFFTSoft to reduce the ringing, and pad x with zeros
+// to avoid the wrap-around.
+// If fLow > fHigh the band is empty, and the result is all 0s.
+// This does not alter x, and returns a new array.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func BandpassFFT(x []float64, sampleRate, fLow, fHigh float64) ([]float64, error) {
+	if err := checkLength("BandpassFFT Input", len(x)); err != nil {
+		return nil, err
+	}
+	return bandpass(x, sampleRate, fLow, fHigh, 0), nil
+}
+
+// BandpassFFTSoft filters the real signal x in the frequency domain like BandpassFFT,
+// but tapers the gain from 1 at the band edges down to 0 over a raised-cosine
+// transition of transition Hz outside [fLow, fHigh], which reduces the ringing.
+// A transition of 0 is the same as BandpassFFT.
+// This does not alter x, and returns a new array.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func BandpassFFTSoft(x []float64, sampleRate, fLow, fHigh, transition float64) ([]float64, error) {
+	if err := checkLength("BandpassFFTSoft Input", len(x)); err != nil {
+		return nil, err
+	}
+	return bandpass(x, sampleRate, fLow, fHigh, transition), nil
+}
+
+// bandpass does the actual work for BandpassFFT and BandpassFFTSoft
+func bandpass(x []float64, sampleRate, fLow, fHigh, transition float64) []float64 {
+	N := len(x)
+	X := Float64ToComplex128Array(x)
+	fft(X)
+	for k := 0; k <= N/2; k++ {
+		g := bandGain(float64(k)*sampleRate/float64(N), fLow, fHigh, transition)
+		X[k] *= complex(g, 0)
+		if k != 0 && k != N-k {
+			X[N-k] *= complex(g, 0)
+		}
+	}
+	ifft(X)
+	return Complex128ToFloat64Array(X)
+}
+
+// bandGain returns the gain at frequency f of a band [fLow, fHigh] with
+// raised-cosine transitions of width transition outside the band
+func bandGain(f, fLow, fHigh, transition float64) float64 {
+	if fLow > fHigh {
+		return 0
+	}
+	d := 0.0 // distance outside the band
+	if f < fLow {
+		d = fLow - f
+	} else if f > fHigh {
+		d = f - fHigh
+	}
+	if d == 0 {
+		return 1
+	}
+	if d >= transition {
+		return 0
+	}
+	return 0.5 * (1 + math.Cos(math.Pi*d/transition))
+}
+
+// FractionalDelay delays x by delay samples, which need not be an integer, with an
+// all-pass filter in the frequency domain, for uses such as beamforming.
+// x is transformed with FFT, each bin k is multiplied by exp(-2πi*f*delay/N),
+// with f the signed frequency of the bin as in FFTFreq, and the result is
+// transformed back with IFFT.
+// The Nyquist bin is its own negative frequency, so it is multiplied by
+// the real cos(π*delay), the average of its two possible phase shifts,
+// which keeps a real x real.
+// The delay is circular: samples delayed past the end of x wrap around to the
+// start, and a negative delay advances x. Pad x with zeros to avoid the wrap-around.
+// An integer delay is a plain circular shift, to within rounding error.
+// This does not alter x, and returns a new array.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func FractionalDelay(x []complex128, delay float64) ([]complex128, error) {
+	if err := checkLength("FractionalDelay Input", len(x)); err != nil {
+		return nil, err
+	}
+	N := len(x)
+	y := ZeroPad(x, N)
+	fft(y)
+	for k := range y {
+		f := k
+		if 2*k > N {
+			f -= N
+		}
+		if 2*k == N {
+			y[k] *= complex(math.Cos(math.Pi*delay), 0)
+			continue
+		}
+		s, c := math.Sincos(-2 * math.Pi * float64(f) * delay / float64(N))
+		y[k] *= complex(c, s)
+	}
+	ifft(y)
+	return y, nil
+}
+
+// SincLowpass generates a windowed-sinc lowpass FIR kernel of numTaps taps, for
+// use with ConvolveReal. cutoff is the -6dB frequency as a fraction of Nyquist,
+// in (0, 1), and the ideal sinc response is multiplied by window to make it finite.
+// The kernel is symmetric, delaying the signal by (numTaps-1)/2 samples,
+// and is scaled to a gain of exactly 1 at DC.
+// Returns nil if numTaps is not positive or cutoff is outside (0, 1).
+func SincLowpass(cutoff float64, numTaps int, window Window) []float64 {
+	if numTaps <= 0 || !(cutoff > 0 && cutoff < 1) {
+		return nil
+	}
+	return sincKernel(cutoff, 0, numTaps, window)
+}
+
+// SincHighpass generates a windowed-sinc highpass FIR kernel of numTaps taps by
+// spectral inversion of SincLowpass(cutoff, numTaps, window), subtracting it from
+// a unit impulse at its center, so the gain is exactly 0 at DC.
+// Returns nil if numTaps is not positive and odd, since an even kernel has no center tap,
+// or if cutoff is outside (0, 1).
+func SincHighpass(cutoff float64, numTaps int, window Window) []float64 {
+	h := SincLowpass(cutoff, numTaps, window)
+	if h == nil || numTaps%2 == 0 {
+		return nil
+	}
+	for i := range h {
+		h[i] = -h[i]
+	}
+	h[numTaps/2] += 1
+	return h
+}
+
+// SincBandpass generates a windowed-sinc bandpass FIR kernel of numTaps taps passing
+// from low to high, as fractions of Nyquist, by shifting a lowpass kernel with a cutoff
+// of half the bandwidth up to the center of the band.
+// The kernel is scaled to a gain of exactly 1 at the center of the band.
+// Returns nil if numTaps is not positive, or unless 0 <= low < high <= 1.
+func SincBandpass(low, high float64, numTaps int, window Window) []float64 {
+	if numTaps <= 0 || !(low >= 0 && low < high && high <= 1) {
+		return nil
+	}
+	return sincKernel((high-low)/2, (high+low)/2, numTaps, window)
+}
+
+// sincKernel does the actual work for SincLowpass and SincBandpass, generating the
+// windowed-sinc lowpass kernel with the given cutoff, modulated up to center,
+// and scaling it to a gain of 1 at center. Both are fractions of Nyquist.
+func sincKernel(cutoff, center float64, numTaps int, window Window) []float64 {
+	h := windowWeights(window, numTaps)
+	mid := float64(numTaps-1) / 2
+	gain := 0.0
+	for i := range h {
+		t := float64(i) - mid
+		h[i] *= cutoff * sinc(cutoff*t)
+		if center != 0 {
+			h[i] *= 2 * math.Cos(math.Pi*center*t)
+		}
+		// The kernel is symmetric, so its response at center is real
+		gain += h[i] * math.Cos(math.Pi*center*t)
+	}
+	for i := range h {
+		h[i] /= gain
+	}
+	return h
+}
+
+// sinc returns the normalized sinc function, sin(πx)/(πx)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
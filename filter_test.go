@@ -0,0 +1,168 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// tone returns n samples of cos(2*Pi*freq*t) at sampleRate, scaled by amplitude
+func tone(n int, freq, sampleRate, amplitude float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = amplitude * math.Cos(2*math.Pi*freq*float64(i)/sampleRate)
+	}
+	return x
+}
+
+func TestBandpassFFT(t *testing.T) {
+	// Test non-powers of 2 return InputSizeError
+	_, err := BandpassFFT(floatRand(17), 1, 0, 0.5)
+	checkIsInputSizeError(t, "BandpassFFT(floatRand(17), 1, 0, 0.5)", err)
+	_, err = BandpassFFTSoft(floatRand(17), 1, 0, 0.5, 0.1)
+	checkIsInputSizeError(t, "BandpassFFTSoft(floatRand(17), 1, 0, 0.5, 0.1)", err)
+	// Test only the in-band tone remains, with tones exactly on bins
+	N, fs := 1024, 1024.0
+	low, mid, high := tone(N, 50, fs, 1), tone(N, 200, fs, 0.5), tone(N, 400, fs, 2)
+	x := make([]float64, N)
+	for i := range x {
+		x[i] = low[i] + mid[i] + high[i]
+	}
+	for _, transition := range []float64{0, 20} {
+		y, err := BandpassFFTSoft(x, fs, 100, 300, transition)
+		if err != nil {
+			t.Errorf("BandpassFFTSoft error: %v", err)
+		}
+		for i := range y {
+			if e := math.Abs(y[i] - mid[i]); e > 1e-9 {
+				t.Errorf("BandpassFFTSoft differs: transition=%v i=%d got=%v expected=%v diff=%v", transition, i, y[i], mid[i], e)
+			}
+		}
+	}
+	// Test the gain halfway through the transition is 0.5
+	y, err := BandpassFFTSoft(low, fs, 60, 300, 20)
+	if err != nil {
+		t.Errorf("BandpassFFTSoft error: %v", err)
+	}
+	for i := range y {
+		if e := math.Abs(y[i] - 0.5*low[i]); e > 1e-9 {
+			t.Errorf("BandpassFFTSoft transition gain differs: i=%d got=%v expected=%v diff=%v", i, y[i], 0.5*low[i], e)
+		}
+	}
+	// Test an empty band gives all 0s
+	y, err = BandpassFFT(x, fs, 300, 100)
+	if err != nil {
+		t.Errorf("BandpassFFT error: %v", err)
+	}
+	for i, v := range y {
+		if v != 0 {
+			t.Errorf("BandpassFFT of an empty band differs: y[%d]=%v expected 0", i, v)
+		}
+	}
+}
+
+// firGain returns the magnitude of the frequency response of the FIR kernel h
+// at f, as a fraction of Nyquist
+func firGain(h []float64, f float64) float64 {
+	var H complex128
+	for i, v := range h {
+		H += complex(v, 0) * cmplx.Exp(complex(0, -math.Pi*f*float64(i)))
+	}
+	return cmplx.Abs(H)
+}
+
+func TestSincFilters(t *testing.T) {
+	// Test invalid arguments return nil
+	if SincLowpass(0.3, 0, Hamming) != nil || SincLowpass(0, 11, Hamming) != nil || SincLowpass(1, 11, Hamming) != nil {
+		t.Errorf("SincLowpass with invalid arguments, expected nil")
+	}
+	if SincHighpass(0.3, 10, Hamming) != nil {
+		t.Errorf("SincHighpass with an even number of taps, expected nil")
+	}
+	if SincBandpass(0.5, 0.2, 11, Hamming) != nil || SincBandpass(0.2, 1.5, 11, Hamming) != nil {
+		t.Errorf("SincBandpass with invalid arguments, expected nil")
+	}
+	// Test the gain of each kernel in its passbands and stopbands
+	for _, c := range []struct {
+		name  string
+		h     []float64
+		gains [][2]float64 // frequency, expected gain
+	}{
+		{"SincLowpass(0.3, 101, Hamming)", SincLowpass(0.3, 101, Hamming), [][2]float64{{0, 1}, {0.1, 1}, {0.3, 0.5}, {0.5, 0}, {0.9, 0}}},
+		{"SincLowpass(0.3, 100, Blackman)", SincLowpass(0.3, 100, Blackman), [][2]float64{{0, 1}, {0.1, 1}, {0.3, 0.5}, {0.5, 0}, {0.9, 0}}},
+		{"SincHighpass(0.3, 101, Hamming)", SincHighpass(0.3, 101, Hamming), [][2]float64{{0, 0}, {0.1, 0}, {0.3, 0.5}, {0.5, 1}, {1, 1}}},
+		{"SincBandpass(0.2, 0.5, 101, Hamming)", SincBandpass(0.2, 0.5, 101, Hamming), [][2]float64{{0, 0}, {0.05, 0}, {0.35, 1}, {0.45, 1}, {0.7, 0}}},
+	} {
+		for i, v := range c.h {
+			if w := c.h[len(c.h)-1-i]; math.Abs(v-w) > 1e-12 {
+				t.Errorf("%s is not symmetric: h[%d]=%v h[%d]=%v", c.name, i, v, len(c.h)-1-i, w)
+			}
+		}
+		for _, g := range c.gains {
+			if e := math.Abs(firGain(c.h, g[0]) - g[1]); e > 0.01 {
+				t.Errorf("%s gain at %v differs: got=%v expected=%v diff=%v", c.name, g[0], firGain(c.h, g[0]), g[1], e)
+			}
+		}
+	}
+	// Test the normalized gains are exact
+	if g := sum(SincLowpass(0.25, 31, Hanning)); math.Abs(g-1) > 1e-12 {
+		t.Errorf("SincLowpass DC gain, got: %v, expected: 1", g)
+	}
+	if g := sum(SincHighpass(0.25, 31, Hanning)); math.Abs(g) > 1e-12 {
+		t.Errorf("SincHighpass DC gain, got: %v, expected: 0", g)
+	}
+	// Test filtering with ConvolveReal keeps a low tone and removes a high one
+	sampleRate := 1000.0
+	low, high := tone(1024, 50, sampleRate, 1), tone(1024, 400, sampleRate, 1)
+	x := make([]float64, len(low))
+	for i := range x {
+		x[i] = low[i] + high[i]
+	}
+	h := SincLowpass(0.4, 101, Hamming)
+	y, _ := ConvolveReal(x, h)
+	// Compare past the start-up transient, allowing for the delay of 50 samples
+	for i := 200; i < 800; i++ {
+		if e := math.Abs(y[i+50] - low[i]); e > 0.01 {
+			t.Errorf("SincLowpass filtered tone differs: i=%d got=%v expected=%v diff=%v", i, y[i+50], low[i], e)
+		}
+	}
+}
+
+func TestFractionalDelay(t *testing.T) {
+	_, err := FractionalDelay(complexRand(12), 0.5)
+	checkIsInputSizeError(t, "FractionalDelay(complexRand(12), 0.5)", err)
+	// Test integer delays are circular shifts, in both directions
+	for N := 1; N < (1 << 8); N <<= 1 {
+		x := complexRand(N)
+		for _, d := range []int{0, 1, 3, -2, N + 1} {
+			y, err := FractionalDelay(x, float64(d))
+			if err != nil {
+				t.Errorf("FractionalDelay error: %v", err)
+			}
+			for n := range y {
+				expect := x[((n-d)%N+N)%N]
+				if e := cmplx.Abs(y[n] - expect); e > 1e-9 {
+					t.Errorf("FractionalDelay by %d differs from a shift: N=%d n=%d got=%v expected=%v diff=%v", d, N, n, y[n], expect, e)
+				}
+			}
+		}
+	}
+	// Test a fractional delay of a band-limited real signal matches sampling it later
+	N := 64
+	sampleRate := float64(N)
+	x := Float64ToComplex128Array(tone(N, 5, sampleRate, 1))
+	y, _ := FractionalDelay(x, 0.3)
+	for n := range y {
+		expect := math.Cos(2 * math.Pi * 5 * (float64(n) - 0.3) / sampleRate)
+		if e := cmplx.Abs(y[n] - complex(expect, 0)); e > 1e-9 {
+			t.Errorf("FractionalDelay by 0.3 differs: n=%d got=%v expected=%v diff=%v", n, y[n], expect, e)
+		}
+	}
+	// Test a real signal stays real, including its Nyquist component
+	y, _ = FractionalDelay(Float64ToComplex128Array(floatRand(32)), 0.7)
+	for n, v := range y {
+		if math.Abs(imag(v)) > 1e-9 {
+			t.Errorf("FractionalDelay of a real signal has an imaginary part: y[%d]=%v", n, v)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package fft
+
+import (
+	"math/bits"
+	"math/cmplx"
+)
+
+// Complex is the set of complex types that FFTGeneric can transform.
+type Complex interface {
+	~complex64 | ~complex128
+}
+
+// FFTGeneric implements the fast Fourier transform on any complex type,
+// such as complex64 or complex128, in the precision of that type.
+// FFT and FFT32 share this implementation.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func FFTGeneric[T Complex](x []T) error {
+	if err := checkLength("FFT Input", len(x)); err != nil {
+		return err
+	}
+	fftGeneric(x)
+	return nil
+}
+
+// IFFTGeneric implements the inverse fast Fourier transform on any complex type,
+// such as complex64 or complex128, in the precision of that type.
+// IFFT and IFFT32 share this implementation.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func IFFTGeneric[T Complex](x []T) error {
+	if err := checkLength("IFFT Input", len(x)); err != nil {
+		return err
+	}
+	ifftGeneric(x)
+	return nil
+}
+
+// fftGeneric does the actual work for FFTGeneric, FFT and FFT32
+func fftGeneric[T Complex](x []T) {
+	N := len(x)
+	// -i, for rotating by a quarter turn
+	mi := T(complex(0, -1))
+	// Handle small N quickly
+	switch N {
+	case 1:
+		return
+	case 2:
+		x[0], x[1] = x[0]+x[1], x[0]-x[1]
+		return
+	case 4:
+		f := (x[1] - x[3]) * mi
+		x[0], x[1], x[2], x[3] = x[0]+x[1]+x[2]+x[3], x[0]-x[2]+f, x[0]-x[1]+x[2]-x[3], x[0]-x[2]-f
+		return
+	}
+	// Reorder the input array.
+	permuteGeneric(x)
+	butterflyGeneric(x)
+}
+
+// butterflyGeneric does the butterfly steps of fftGeneric on x, which must already
+// be permuted into bit-reversed order
+func butterflyGeneric[T Complex](x []T) {
+	N := len(x)
+	// -i, for rotating by a quarter turn
+	mi := T(complex(0, -1))
+	switch N {
+	case 1:
+		return
+	case 2:
+		x[0], x[1] = x[0]+x[1], x[0]-x[1]
+		return
+	}
+	// First 2 steps
+	for i := 0; i < N; i += 4 {
+		f := (x[i+2] - x[i+3]) * mi
+		x[i], x[i+1], x[i+2], x[i+3] = x[i]+x[i+1]+x[i+2]+x[i+3], x[i]-x[i+1]+f, x[i]-x[i+2]+x[i+1]-x[i+3], x[i]-x[i+1]-f
+	}
+	// Remaining steps, as radix-4 stages that each combine 2 radix-2 steps,
+	// preceded by a single radix-2 step if there is an odd number of them
+	w := mi
+	n := 4
+	if bits.Len(uint(N))%2 == 0 && n < N {
+		w = T(cmplx.Sqrt(complex128(w)))
+		radix2Generic(x, n, w)
+		n <<= 1
+	}
+	for ; n < N; n <<= 2 {
+		w = T(cmplx.Sqrt(complex128(w)))
+		w = T(cmplx.Sqrt(complex128(w)))
+		radix4Generic(x, n, w)
+	}
+}
+
+// radix2Generic does a single radix-2 butterfly step on x, combining pairs of
+// transforms of length n, where w = exp(-iπ/n)
+func radix2Generic[T Complex](x []T, n int, w T) {
+	for o := 0; o < len(x); o += (n << 1) {
+		wj := T(1)
+		for k := 0; k < n; k++ {
+			i := k + o
+			f := wj * x[i+n]
+			x[i], x[i+n] = x[i]+f, x[i]-f
+			wj *= w
+		}
+	}
+}
+
+// radix4Generic does 2 radix-2 butterfly steps on x at once, combining groups of 4
+// transforms of length n, where w = exp(-iπ/2n).
+// In bit-reversed order, the 4 transforms in each group are of the inputs
+// congruent to 0, 2, 1 and 3 mod 4, and they are replaced in-place by the
+// 4 quarters of the combined transform.
+// This takes 3 complex multiplications per 4 points, against 4 for the 2 radix-2
+// steps, and makes half as many passes over x.
+func radix4Generic[T Complex](x []T, n int, w T) {
+	// -i, for rotating by a quarter turn
+	mi := T(complex(0, -1))
+	w2 := w * w
+	w3 := w2 * w
+	for o := 0; o < len(x); o += (n << 2) {
+		// wj1, wj2 and wj3 are w^k, w^2k and w^3k
+		wj1, wj2, wj3 := T(1), T(1), T(1)
+		for k := o; k < o+n; k++ {
+			t0 := x[k]
+			t1 := wj1 * x[k+2*n]
+			t2 := wj2 * x[k+n]
+			t3 := wj3 * x[k+3*n]
+			s0, s1 := t0+t2, t0-t2
+			s2, s3 := t1+t3, t1-t3
+			s3 *= mi
+			x[k], x[k+n], x[k+2*n], x[k+3*n] = s0+s2, s1+s3, s0-s2, s1-s3
+			wj1 *= w
+			wj2 *= w2
+			wj3 *= w3
+		}
+	}
+}
+
+// ifftGeneric does the actual work for IFFTGeneric, IFFT and IFFT32
+func ifftGeneric[T Complex](x []T) {
+	N := len(x)
+	// Reverse the input vector
+	for i := 1; i < N/2; i++ {
+		j := N - i
+		x[i], x[j] = x[j], x[i]
+	}
+
+	// Do the transform.
+	fftGeneric(x)
+
+	// Scale the output by 1/N
+	invN := T(complex(1.0/float64(N), 0))
+	for i := 0; i < N; i++ {
+		x[i] *= invN
+	}
+}
+
+// permuteGeneric permutes the input vector using bit reversal.
+// Uses an in-place algorithm that runs in O(N) time and O(1) additional space.
+func permuteGeneric[T Complex](x []T) {
+	N := len(x)
+	// Handle small N quickly
+	switch N {
+	case 1, 2:
+		return
+	case 4:
+		x[1], x[2] = x[2], x[1]
+		return
+	case 8:
+		x[1], x[4] = x[4], x[1]
+		x[3], x[6] = x[6], x[3]
+		return
+	}
+	shift := permutationShift(N)
+	N2 := N >> 1
+	for i := 0; i < N; i += 2 {
+		ind := permutationIndex(i, shift)
+		// Skip cases where low bit isn't set while high bit is
+		// This eliminates 25% of iterations
+		if i < N2 {
+			if ind > i {
+				x[i], x[ind] = x[ind], x[i]
+			}
+		}
+		ind |= N2 // Fast way to get int(bits.Reverse64(uint64(i+1)) >> shift) here
+		if ind > i+1 {
+			x[i+1], x[ind] = x[ind], x[i+1]
+		}
+	}
+}
+
+// permutationShift returns the shift that permutationIndex needs for vectors of length N,
+// which must be a power of 2.
+func permutationShift(N int) uint {
+	return 64 - uint(bits.Len64(uint64(N-1)))
+}
+
+// permutationIndex returns the bit-reversed index of i, which must be in [0, N),
+// in a vector of length N, given shift = permutationShift(N).
+// The arithmetic is all on uint64, so it can't overflow for any N up to MaxLength.
+func permutationIndex(i int, shift uint) int {
+	return int(bits.Reverse64(uint64(i)) >> shift)
+}
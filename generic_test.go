@@ -0,0 +1,50 @@
+package fft
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+// namedComplex checks FFTGeneric accepts types with an underlying complex type
+type namedComplex complex128
+
+func TestFFTGeneric(t *testing.T) {
+	// Test non-powers of 2 return InputSizeError
+	checkIsInputSizeError(t, "FFTGeneric(complexRand(17))", FFTGeneric(complexRand(17)))
+	checkIsInputSizeError(t, "IFFTGeneric(complexRand(17))", IFFTGeneric(complexRand(17)))
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := complexRand(N)
+		y1 := slowFFT(copyVector(x))
+		// Test FFTGeneric on a named complex type matches slowFFT
+		y2 := make([]namedComplex, N)
+		for i, v := range x {
+			y2[i] = namedComplex(v)
+		}
+		if err := FFTGeneric(y2); err != nil {
+			t.Errorf("FFTGeneric error: %v", err)
+		}
+		for i := range y1 {
+			if e := cmplx.Abs(y1[i] - complex128(y2[i])); e > 1e-9 {
+				t.Errorf("slowFFT and FFTGeneric differ: N=%d i=%d y1=%v y2=%v diff=%v", N, i, y1[i], y2[i], e)
+			}
+		}
+		if err := IFFTGeneric(y2); err != nil {
+			t.Errorf("IFFTGeneric error: %v", err)
+		}
+		for i := range x {
+			if e := cmplx.Abs(x[i] - complex128(y2[i])); e > 1e-9 {
+				t.Errorf("IFFTGeneric(FFTGeneric(x)) differs from x: N=%d i=%d got=%v expected=%v diff=%v", N, i, y2[i], x[i], e)
+			}
+		}
+		// Test FFTGeneric on complex64 is identical to FFT32
+		z1 := Complex128ToComplex64(x)
+		z2 := Complex128ToComplex64(x)
+		FFT32(z1)
+		FFTGeneric(z2)
+		for i := range z1 {
+			if z1[i] != z2[i] {
+				t.Errorf("FFT32 and FFTGeneric differ: N=%d i=%d z1=%v z2=%v", N, i, z1[i], z2[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package fft
+
+import (
+	"math"
+)
+
+// Goertzel computes a single bin of the discrete Fourier transform of x using
+// the Goertzel algorithm, returning the same value as FFT would for bin targetBin.
+// Takes O(N) run time and O(1) additional space, so is cheaper than a full FFT
+// when only a handful of bins are needed.
+// x may be of any length. targetBin is taken modulo len(x).
+func Goertzel(x []float64, targetBin int) complex128 {
+	N := len(x)
+	if N == 0 {
+		return 0
+	}
+	s, c := math.Sincos(2 * math.Pi * float64(targetBin%N) / float64(N))
+	coeff := 2 * c
+	var s1, s2 float64
+	for _, v := range x {
+		s1, s2 = v+coeff*s1-s2, s1
+	}
+	// One more step of the recurrence with a 0 input gives y[N] = X[k]
+	s1, s2 = coeff*s1-s2, s1
+	return complex(s1-c*s2, s*s2)
+}
+
+// GoertzelFreq computes the discrete Fourier transform of x at the bin nearest
+// to freq, for x sampled at sampleRate, using the Goertzel algorithm.
+// x may be of any length.
+func GoertzelFreq(x []float64, freq, sampleRate float64) complex128 {
+	k := int(math.Round(freq * float64(len(x)) / sampleRate))
+	if k < 0 {
+		k += len(x)
+	}
+	return Goertzel(x, k)
+}
+
+// GoertzelBank computes the bins of the discrete Fourier transform of x listed
+// in bins using the Goertzel algorithm, returning one result per entry of bins,
+// the same values Goertzel would return for each.
+// The recurrences for groups of 4 bins are run together, sharing one traversal
+// of x, so takes O(N*len(bins)) run time, and O(len(bins)) additional space.
+// x may be of any length. Each bin is taken modulo len(x).
+func GoertzelBank(x []float64, bins []int) []complex128 {
+	r := make([]complex128, len(bins))
+	N := len(x)
+	if N == 0 {
+		return r
+	}
+	j := 0
+	for ; j+4 <= len(bins); j += 4 {
+		var s, c [4]float64
+		for i := range s {
+			s[i], c[i] = math.Sincos(2 * math.Pi * float64(bins[j+i]%N) / float64(N))
+		}
+		c0, c1, c2, c3 := 2*c[0], 2*c[1], 2*c[2], 2*c[3]
+		var a1, a2, b1, b2, d1, d2, e1, e2 float64
+		for _, v := range x {
+			a1, a2 = v+c0*a1-a2, a1
+			b1, b2 = v+c1*b1-b2, b1
+			d1, d2 = v+c2*d1-d2, d1
+			e1, e2 = v+c3*e1-e2, e1
+		}
+		// One more step of each recurrence with a 0 input gives y[N] = X[k]
+		a1, a2 = c0*a1-a2, a1
+		b1, b2 = c1*b1-b2, b1
+		d1, d2 = c2*d1-d2, d1
+		e1, e2 = c3*e1-e2, e1
+		r[j] = complex(a1-c[0]*a2, s[0]*a2)
+		r[j+1] = complex(b1-c[1]*b2, s[1]*b2)
+		r[j+2] = complex(d1-c[2]*d2, s[2]*d2)
+		r[j+3] = complex(e1-c[3]*e2, s[3]*e2)
+	}
+	for ; j < len(bins); j++ {
+		r[j] = Goertzel(x, bins[j])
+	}
+	return r
+}
@@ -0,0 +1,77 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestGoertzel(t *testing.T) {
+	// Test Goertzel of an empty input is 0
+	if r := Goertzel(nil, 3); r != 0 {
+		t.Errorf("Goertzel(nil, 3), got: %v, expected: 0", r)
+	}
+	// Test Goertzel(x, k) == slowFFT(x)[k] for any length, including non-powers of 2
+	for N := 1; N < 100; N++ {
+		x := floatRand(N)
+		y := slowFFT(Float64ToComplex128Array(x))
+		for k := 0; k < N; k++ {
+			if e := cmplx.Abs(y[k] - Goertzel(x, k)); e > 1e-9 {
+				t.Errorf("slowFFT and Goertzel differ: N=%d k=%d y[%d]=%v, Goertzel=%v, diff=%v", N, k, k, y[k], Goertzel(x, k), e)
+			}
+		}
+	}
+	// Test GoertzelFreq matches the FFT bin for a few sinusoids
+	sampleRate := 8000.0
+	for _, freq := range []float64{697, 941, 1209, 1633} {
+		x := make([]float64, 1024)
+		for i := range x {
+			x[i] = math.Sin(2 * math.Pi * freq * float64(i) / sampleRate)
+		}
+		y := Float64ToComplex128Array(x)
+		if err := FFT(y); err != nil {
+			t.Fatalf("FFT error: %v", err)
+		}
+		k := int(math.Round(freq * 1024 / sampleRate))
+		r := GoertzelFreq(x, freq, sampleRate)
+		if e := cmplx.Abs(y[k] - r); e > 1e-9 {
+			t.Errorf("FFT and GoertzelFreq differ: freq=%v y[%d]=%v, GoertzelFreq=%v, diff=%v", freq, k, y[k], r, e)
+		}
+	}
+}
+
+func TestGoertzelBank(t *testing.T) {
+	// Test GoertzelBank of an empty input is all 0
+	if r := GoertzelBank(nil, []int{1, 2, 3}); len(r) != 3 || r[0] != 0 || r[1] != 0 || r[2] != 0 {
+		t.Errorf("GoertzelBank(nil, []int{1, 2, 3}), got: %v, expected: [0 0 0]", r)
+	}
+	// Test GoertzelBank(x, bins)[j] == Goertzel(x, bins[j]) for bin counts around the group size
+	for _, N := range []int{1, 7, 64, 205} {
+		x := floatRand(N)
+		for nb := 0; nb <= 11; nb++ {
+			bins := make([]int, nb)
+			for j := range bins {
+				bins[j] = (j*37 + 5) % (2 * N)
+			}
+			r := GoertzelBank(x, bins)
+			if len(r) != nb {
+				t.Fatalf("GoertzelBank length, got: %d, expected: %d", len(r), nb)
+			}
+			for j, k := range bins {
+				if e := cmplx.Abs(r[j] - Goertzel(x, k)); e > 1e-9 {
+					t.Errorf("Goertzel and GoertzelBank differ: N=%d bins=%v j=%d Goertzel=%v, GoertzelBank=%v, diff=%v", N, bins, j, Goertzel(x, k), r[j], e)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkGoertzelBank(b *testing.B) {
+	// 8 DTMF tones in a 205 sample frame at 8kHz
+	x := floatRand(205)
+	bins := []int{18, 20, 22, 24, 31, 34, 38, 42}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GoertzelBank(x, bins)
+	}
+}
@@ -0,0 +1,79 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// EnforceHermitian makes the spectrum x conjugate-symmetric, x[k] == conj(x[N-k]),
+// in-place, so that its IFFT is purely real. Each bin is averaged with the conjugate
+// of its mirror, and the DC bin x[0] and the Nyquist bin x[N/2], which are their own
+// mirrors, keep only their real parts.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func EnforceHermitian(x []complex128) error {
+	if err := checkLength("EnforceHermitian Input", len(x)); err != nil {
+		return err
+	}
+	N := len(x)
+	x[0] = complex(real(x[0]), 0)
+	for k := 1; k < (N+1)/2; k++ {
+		v := (x[k] + cmplx.Conj(x[N-k])) / 2
+		x[k], x[N-k] = v, cmplx.Conj(v)
+	}
+	if N > 1 {
+		x[N/2] = complex(real(x[N/2]), 0)
+	}
+	return nil
+}
+
+// IsHermitian reports whether the spectrum x is conjugate-symmetric within tol,
+// |x[k] - conj(x[N-k])| <= tol for every k, so that its IFFT is purely real.
+// The DC bin x[0] and the Nyquist bin x[N/2], which are their own mirrors,
+// must have imaginary parts within tol of 0.
+// Returns false, rather than an error, if len(x) is not a perfect power of 2.
+func IsHermitian(x []complex128, tol float64) bool {
+	N := len(x)
+	if !IsPow2(N) {
+		return false
+	}
+	if math.Abs(imag(x[0])) > tol || math.Abs(imag(x[N/2])) > tol {
+		return false
+	}
+	for k := 1; k < (N+1)/2; k++ {
+		if cmplx.Abs(x[k]-cmplx.Conj(x[N-k])) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandHermitian reconstructs the full length n spectrum of a real signal from
+// its n/2+1 non-redundant bins half, by mirroring with conjugation, x[n-k] = conj(half[k]).
+// This does not alter half, and returns a new array.
+// n must be positive and len(half) must equal n/2+1, otherwise this will return an error.
+func ExpandHermitian(half []complex128, n int) ([]complex128, error) {
+	if err := checkPositive("ExpandHermitian length", n); err != nil {
+		return nil, err
+	}
+	if err := checkZero("difference in ExpandHermitian input length and n/2+1", len(half)-(n/2+1)); err != nil {
+		return nil, err
+	}
+	x := make([]complex128, n)
+	copy(x, half)
+	for k := 1; k < (n+1)/2; k++ {
+		x[n-k] = cmplx.Conj(half[k])
+	}
+	return x, nil
+}
+
+// CompactHermitian returns the len(x)/2+1 non-redundant bins of x, the spectrum
+// of a real signal, in a new array. This is the inverse of ExpandHermitian.
+// Returns nil if x is empty.
+func CompactHermitian(x []complex128) []complex128 {
+	if len(x) == 0 {
+		return nil
+	}
+	half := make([]complex128, len(x)/2+1)
+	copy(half, x)
+	return half
+}
@@ -0,0 +1,101 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestEnforceHermitian(t *testing.T) {
+	// Test EnforceHermitian of non-powers of 2 returns InputSizeError
+	checkIsInputSizeError(t, "EnforceHermitian(complexRand(17))", EnforceHermitian(complexRand(17)))
+	for N := 1; N < (1 << 11); N <<= 1 {
+		// Test the IFFT of an enforced spectrum is real
+		x := complexRand(N)
+		if err := EnforceHermitian(x); err != nil {
+			t.Errorf("EnforceHermitian error: %v", err)
+		}
+		y := copyVector(x)
+		IFFT(y)
+		for i, v := range y {
+			if math.Abs(imag(v)) > 1e-12 {
+				t.Errorf("IFFT of EnforceHermitian spectrum isn't real: N=%d y[%d]=%v", N, i, v)
+			}
+		}
+		// Test the spectrum of a real signal is unchanged
+		z := Float64ToComplex128Array(floatRand(N))
+		FFT(z)
+		w := copyVector(z)
+		EnforceHermitian(w)
+		for k := range z {
+			if e := cmplx.Abs(z[k] - w[k]); e > 1e-12 {
+				t.Errorf("EnforceHermitian changed a real signal's spectrum: N=%d z[%d]=%v w[%d]=%v", N, k, z[k], k, w[k])
+			}
+		}
+	}
+}
+
+func TestIsHermitian(t *testing.T) {
+	// Test non-powers of 2 return false without panicking
+	for _, n := range []int{0, 3, 12} {
+		if IsHermitian(make([]complex128, n), 1e-9) {
+			t.Errorf("IsHermitian of length %d, got: true, expected: false", n)
+		}
+	}
+	for N := 1; N < (1 << 8); N <<= 1 {
+		// Test the FFT of a real signal is Hermitian, and a random spectrum isn't
+		x := Float64ToComplex128Array(floatRand(N))
+		fft(x)
+		if !IsHermitian(x, 1e-9) {
+			t.Errorf("IsHermitian of the FFT of a real signal, N=%d, got: false, expected: true", N)
+		}
+		if N > 1 && IsHermitian(complexRand(N), 1e-9) {
+			t.Errorf("IsHermitian of a random spectrum, N=%d, got: true, expected: false", N)
+		}
+		// Test a perturbation is caught only when it exceeds tol
+		for _, k := range []int{0, N / 2, N / 3, N - 1} {
+			y := copyVector(x)
+			y[k] += 1e-3i
+			if IsHermitian(y, 1e-6) {
+				t.Errorf("IsHermitian with y[%d] perturbed, N=%d, got: true, expected: false", k, N)
+			}
+			if !IsHermitian(y, 1e-2) {
+				t.Errorf("IsHermitian with y[%d] perturbed within tol, N=%d, got: false, expected: true", k, N)
+			}
+		}
+		// Test EnforceHermitian makes any spectrum Hermitian
+		y := complexRand(N)
+		EnforceHermitian(y)
+		if !IsHermitian(y, 1e-12) {
+			t.Errorf("IsHermitian after EnforceHermitian, N=%d, got: false, expected: true", N)
+		}
+	}
+}
+
+func TestExpandHermitian(t *testing.T) {
+	// Test invalid lengths return InputSizeError
+	_, err := ExpandHermitian(complexRand(5), 0)
+	checkIsInputSizeError(t, "ExpandHermitian(complexRand(5), 0)", err)
+	_, err = ExpandHermitian(complexRand(5), 16)
+	checkIsInputSizeError(t, "ExpandHermitian(complexRand(5), 16)", err)
+	if CompactHermitian(nil) != nil {
+		t.Errorf("CompactHermitian(nil), expected nil")
+	}
+	// Test ExpandHermitian(CompactHermitian(FFT(x))) == FFT(x) for real x, for even and odd lengths
+	for n := 1; n < 100; n++ {
+		x := slowFFT(Float64ToComplex128Array(floatRand(n)))
+		half := CompactHermitian(x)
+		if len(half) != n/2+1 {
+			t.Errorf("CompactHermitian length, got: %d, expected: %d", len(half), n/2+1)
+		}
+		y, err := ExpandHermitian(half, n)
+		if err != nil {
+			t.Errorf("ExpandHermitian error: %v", err)
+		}
+		for k := range x {
+			if e := cmplx.Abs(x[k] - y[k]); e > 1e-9 {
+				t.Errorf("ExpandHermitian(CompactHermitian(x)) differs: n=%d x[%d]=%v y[%d]=%v", n, k, x[k], k, y[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package fft
+
+// Hilbert computes the analytic signal of real input x using the Hilbert transform,
+// matching scipy.signal.hilbert. The real part of the result is x, and the
+// imaginary part is the Hilbert transform of x.
+// The negative frequency bins of the FFT of x are zeroed and the positive
+// frequency bins doubled, leaving the DC and Nyquist bins unchanged.
+// This does not alter x, and returns a new array.
+// len(x) must be a perfect power of 2, otherwise this will return an error.
+func Hilbert(x []float64) ([]complex128, error) {
+	if err := checkLength("Hilbert Input", len(x)); err != nil {
+		return nil, err
+	}
+	N := len(x)
+	y := Float64ToComplex128Array(x)
+	fft(y)
+	for k := 1; k < (N+1)/2; k++ {
+		y[k] *= 2
+	}
+	for k := N/2 + 1; k < N; k++ {
+		y[k] = 0
+	}
+	ifft(y)
+	return y, nil
+}
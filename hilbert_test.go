@@ -0,0 +1,45 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestHilbert(t *testing.T) {
+	// Test Hilbert of non-powers of 2 returns InputSizeError
+	_, err := Hilbert(floatRand(17))
+	checkIsInputSizeError(t, "Hilbert(floatRand(17))", err)
+	// Test the real part of Hilbert(x) == x
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N)
+		y, err := Hilbert(x)
+		if err != nil {
+			t.Errorf("Hilbert error: %v", err)
+		}
+		for i := range x {
+			if e := math.Abs(x[i] - real(y[i])); e > 1e-9 {
+				t.Errorf("Hilbert real part differs: N=%d x[%d]=%v y[%d]=%v diff=%v", N, i, x[i], i, y[i], e)
+			}
+		}
+	}
+	// Test Hilbert of a pure cosine is exp(i*w*t), with a constant magnitude
+	N := 256
+	x := make([]float64, N)
+	w := 2 * math.Pi * 10 / float64(N)
+	for i := range x {
+		x[i] = 3 * math.Cos(w*float64(i))
+	}
+	y, err := Hilbert(x)
+	if err != nil {
+		t.Fatalf("Hilbert error: %v", err)
+	}
+	for i := range y {
+		if e := cmplx.Abs(y[i] - cmplx.Rect(3, w*float64(i))); e > 1e-9 {
+			t.Errorf("Hilbert of cosine differs: y[%d]=%v expected=%v diff=%v", i, y[i], cmplx.Rect(3, w*float64(i)), e)
+		}
+		if e := math.Abs(cmplx.Abs(y[i]) - 3); e > 1e-9 {
+			t.Errorf("Hilbert of cosine has non-constant magnitude: |y[%d]|=%v", i, cmplx.Abs(y[i]))
+		}
+	}
+}
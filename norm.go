@@ -0,0 +1,85 @@
+package fft
+
+import (
+	"math"
+)
+
+// Norm selects the scaling convention applied by FFTNorm and IFFTNorm,
+// matching the norm parameter of numpy.fft.
+type Norm int
+
+const (
+	// Backward leaves the forward transform unscaled and scales the inverse by 1/N.
+	// This is the convention used by FFT and IFFT.
+	Backward Norm = iota
+	// Forward scales the forward transform by 1/N and leaves the inverse unscaled.
+	Forward
+	// Ortho scales both the forward and inverse transforms by 1/sqrt(N).
+	Ortho
+)
+
+// FFTNorm implements the fast Fourier transform with the scaling convention norm.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, and norm must be Backward, Forward or Ortho,
+// otherwise this will return an error.
+func FFTNorm(x []complex128, norm Norm) error {
+	if err := checkLength("FFT Input", len(x)); err != nil {
+		return err
+	}
+	if err := checkNorm("FFTNorm norm", norm); err != nil {
+		return err
+	}
+	fft(x)
+	N := float64(len(x))
+	switch norm {
+	case Forward:
+		scale(x, 1/N)
+	case Ortho:
+		scale(x, 1/math.Sqrt(N))
+	}
+	return nil
+}
+
+// IFFTNorm implements the inverse fast Fourier transform with the scaling convention norm.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must be a perfect power of 2, and norm must be Backward, Forward or Ortho,
+// otherwise this will return an error.
+func IFFTNorm(x []complex128, norm Norm) error {
+	if err := checkLength("IFFT Input", len(x)); err != nil {
+		return err
+	}
+	if err := checkNorm("IFFTNorm norm", norm); err != nil {
+		return err
+	}
+	N := float64(len(x))
+	switch norm {
+	case Forward:
+		reverse(x[1:])
+		fft(x)
+	case Ortho:
+		reverse(x[1:])
+		fft(x)
+		scale(x, 1/math.Sqrt(N))
+	default:
+		ifft(x)
+	}
+	return nil
+}
+
+// checkNorm checks that norm is one of the defined Norm values
+func checkNorm(Context string, norm Norm) error {
+	if norm < Backward || norm > Ortho {
+		return &InputSizeError{Context: Context, Requirement: "Backward, Forward or Ortho", Size: int(norm), Err: ErrOutOfRange}
+	}
+	return nil
+}
+
+// scale multiplies each entry in x by s, in-place
+func scale(x []complex128, s float64) {
+	c := complex(s, 0)
+	for i := range x {
+		x[i] *= c
+	}
+}
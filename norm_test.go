@@ -0,0 +1,59 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFFTNorm(t *testing.T) {
+	// Test FFTNorm and IFFTNorm of non-powers of 2 returns InputSizeError
+	checkIsInputSizeError(t, "FFTNorm(complexRand(17), Ortho)", FFTNorm(complexRand(17), Ortho))
+	checkIsInputSizeError(t, "IFFTNorm(complexRand(17), Ortho)", IFFTNorm(complexRand(17), Ortho))
+	// Test unknown Norm values return InputSizeError, leaving x unaltered
+	for _, norm := range []Norm{Norm(7), Norm(-1), Ortho + 1} {
+		x := complexRand(8)
+		y := copyVector(x)
+		checkIsInputSizeError(t, fmt.Sprintf("FFTNorm(x, Norm(%d))", norm), FFTNorm(y, norm))
+		checkIsInputSizeError(t, fmt.Sprintf("IFFTNorm(x, Norm(%d))", norm), IFFTNorm(y, norm))
+		for i := range x {
+			if x[i] != y[i] {
+				t.Errorf("FFTNorm with Norm(%d) altered x", norm)
+				break
+			}
+		}
+	}
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := complexRand(N)
+		y := slowFFT(x)
+		for _, test := range []struct {
+			norm  Norm
+			scale float64
+		}{
+			{Backward, 1},
+			{Forward, 1 / float64(N)},
+			{Ortho, 1 / math.Sqrt(float64(N))},
+		} {
+			// Test FFTNorm(x) == scale*slowFFT(x)
+			z := copyVector(x)
+			if err := FFTNorm(z, test.norm); err != nil {
+				t.Errorf("FFTNorm error: %v", err)
+			}
+			for i := range z {
+				if e := cmplx.Abs(y[i]*complex(test.scale, 0) - z[i]); e > 1e-9 {
+					t.Errorf("FFTNorm(x, %d) differs: N=%d i=%d got=%v expected=%v diff=%v", test.norm, N, i, z[i], y[i]*complex(test.scale, 0), e)
+				}
+			}
+			// Test IFFTNorm(FFTNorm(x)) == x
+			if err := IFFTNorm(z, test.norm); err != nil {
+				t.Errorf("IFFTNorm error: %v", err)
+			}
+			for i := range z {
+				if e := cmplx.Abs(x[i] - z[i]); e > 1e-9 {
+					t.Errorf("IFFTNorm(FFTNorm(x, %d), %d) differs: N=%d i=%d got=%v expected=%v diff=%v", test.norm, test.norm, N, i, z[i], x[i], e)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package fft
+
+// OverlapAdd computes the discrete convolution of a long (or unbounded)
+// signal against a fixed kernel, one block at a time, using the overlap-add
+// method. The FFT of the kernel is computed once, and the tail of each block's
+// convolution is carried over into the next call to Process.
+//
+// Concatenating the outputs of every call to Process, followed by Flush,
+// gives the same result as Convolve on the entire signal.
+//
+// An OverlapAdd is not safe for concurrent use.
+type OverlapAdd struct {
+	kernel    []complex128 // FFT of the 0-padded kernel
+	blockSize int
+	buf       []complex128 // scratch space for each block
+	tail      []complex128 // carried over convolution tail, len(kernel)-1
+}
+
+// NewOverlapAdd creates an OverlapAdd for convolving against kernel, in blocks of
+// at most blockSize samples. The FFT length is the next power of 2 from
+// blockSize+len(kernel)-1.
+// kernel must be non-empty and blockSize must be positive, otherwise this will return an error.
+func NewOverlapAdd(kernel []complex128, blockSize int) (*OverlapAdd, error) {
+	if err := checkPositive("OverlapAdd kernel length", len(kernel)); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("OverlapAdd block size", blockSize); err != nil {
+		return nil, err
+	}
+	N := NextPow2(blockSize + len(kernel) - 1)
+	k := ZeroPad(kernel, N)
+	fft(k)
+	return &OverlapAdd{
+		kernel:    k,
+		blockSize: blockSize,
+		buf:       make([]complex128, N),
+		tail:      make([]complex128, len(kernel)-1),
+	}, nil
+}
+
+// Process convolves the next block of the signal against the kernel, returning
+// the len(block) finalized output samples. block may be of any length, and is
+// internally split into pieces of at most blockSize samples.
+// This does not alter block.
+func (o *OverlapAdd) Process(block []complex128) []complex128 {
+	out := make([]complex128, 0, len(block))
+	for len(block) > 0 {
+		n := len(block)
+		if n > o.blockSize {
+			n = o.blockSize
+		}
+		out = append(out, o.processBlock(block[:n])...)
+		block = block[n:]
+	}
+	return out
+}
+
+// Flush returns the remaining len(kernel)-1 output samples carried over from
+// previous calls to Process, and resets the OverlapAdd for a new signal.
+func (o *OverlapAdd) Flush() []complex128 {
+	out := make([]complex128, len(o.tail))
+	copy(out, o.tail)
+	for i := range o.tail {
+		o.tail[i] = 0
+	}
+	return out
+}
+
+// processBlock does the actual work for Process on a single block with len(block) <= blockSize.
+// The returned slice aliases the internal scratch buffer.
+func (o *OverlapAdd) processBlock(block []complex128) []complex128 {
+	copy(o.buf, block)
+	for i := len(block); i < len(o.buf); i++ {
+		o.buf[i] = 0
+	}
+	fft(o.buf)
+	for i := range o.buf {
+		o.buf[i] *= o.kernel[i]
+	}
+	ifft(o.buf)
+	for i, v := range o.tail {
+		o.buf[i] += v
+	}
+	copy(o.tail, o.buf[len(block):len(block)+len(o.tail)])
+	return o.buf[:len(block)]
+}
+
+// OverlapSaveConvolve computes the discrete convolution of x and kernel using
+// FFT, like Convolve, but with the overlap-save method: x is processed in
+// overlapping blocks, against the transform of the kernel computed once.
+// The FFT length is a few times len(kernel), rounded to a power of 2, rather than
+// len(x)+len(kernel)-1, which is far more efficient when x is much longer than kernel.
+// This does not alter x or kernel, and returns a new array of length len(x)+len(kernel)-1.
+func OverlapSaveConvolve(x, kernel []complex128) ([]complex128, error) {
+	if len(x) == 0 && len(kernel) == 0 {
+		return nil, nil
+	}
+	n := len(x) + len(kernel) - 1
+	if len(x) == 0 || len(kernel) == 0 {
+		return make([]complex128, max(n, 0)), nil
+	}
+	M := len(kernel)
+	N := min(NextPow2(4*M), NextPow2(n))
+	L := N - (M - 1) // new output samples per block
+	k := ZeroPad(kernel, N)
+	fft(k)
+	buf := make([]complex128, N)
+	y := make([]complex128, n)
+	for start := 0; start < n; start += L {
+		// Each block starts M-1 samples before its outputs, which are discarded
+		// as they wrap around in the circular convolution
+		for i := range buf {
+			j := start - (M - 1) + i
+			if j >= 0 && j < len(x) {
+				buf[i] = x[j]
+			} else {
+				buf[i] = 0
+			}
+		}
+		fft(buf)
+		for i := range buf {
+			buf[i] *= k[i]
+		}
+		ifft(buf)
+		copy(y[start:min(start+L, n)], buf[M-1:])
+	}
+	return y, nil
+}
+
+// PartitionedConvolver computes the discrete convolution of a stream of fixed-size
+// blocks against a fixed kernel with constant latency, returning one output block
+// per input block, as in a low-latency convolution reverb.
+// The kernel is split into partitions of blockSize samples, each transformed once,
+// and each input block is transformed once and kept in a frequency-domain delay
+// line, so each call computes the sum of the products of the last few input
+// spectra and the partition spectra, with a single FFT and IFFT of 2*blockSize
+// (uniformly-partitioned overlap-save).
+//
+// Concatenating the outputs of every call to Process gives the same result as
+// Convolve on the concatenated input blocks, truncated to their length. To get
+// the remaining len(kernel)-1 samples of the tail, Process blocks of 0s.
+//
+// A PartitionedConvolver is not safe for concurrent use.
+type PartitionedConvolver struct {
+	blockSize  int
+	partitions [][]complex128 // FFTs of the 0-padded kernel partitions
+	fdl        [][]complex128 // FFTs of the most recent input blocks
+	head       int            // index in fdl of the most recent input block
+	input      []complex128   // the previous and current input blocks
+	acc        []complex128   // accumulated spectrum, and then output
+	out        []complex128   // the output block
+}
+
+// NewPartitionedConvolver creates a PartitionedConvolver for convolving against kernel,
+// in blocks of blockSize samples, using FFTs of length 2*blockSize.
+// kernel must be non-empty and blockSize must be a perfect power of 2, otherwise this will return an error.
+func NewPartitionedConvolver(kernel []complex128, blockSize int) (*PartitionedConvolver, error) {
+	if err := checkPositive("PartitionedConvolver kernel length", len(kernel)); err != nil {
+		return nil, err
+	}
+	if err := checkLength("PartitionedConvolver block size", blockSize); err != nil {
+		return nil, err
+	}
+	P := (len(kernel) + blockSize - 1) / blockSize
+	c := &PartitionedConvolver{
+		blockSize:  blockSize,
+		partitions: make([][]complex128, P),
+		fdl:        make([][]complex128, P),
+		input:      make([]complex128, 2*blockSize),
+		acc:        make([]complex128, 2*blockSize),
+		out:        make([]complex128, blockSize),
+	}
+	for p := range c.partitions {
+		c.partitions[p] = ZeroPad(kernel[p*blockSize:min((p+1)*blockSize, len(kernel))], 2*blockSize)
+		fft(c.partitions[p])
+		c.fdl[p] = make([]complex128, 2*blockSize)
+	}
+	return c, nil
+}
+
+// Process convolves the next block of the stream against the kernel, returning
+// the blockSize output samples for it.
+// The returned block is reused by the next call to Process.
+// This does not alter in.
+// len(in) must equal the block size, otherwise this will panic.
+func (c *PartitionedConvolver) Process(in []complex128) []complex128 {
+	B := c.blockSize
+	if len(in) != B {
+		panic("fft: PartitionedConvolver.Process requires len(in) to equal the block size")
+	}
+	// Slide the input window along by one block, and transform it into the delay line
+	copy(c.input, c.input[B:])
+	copy(c.input[B:], in)
+	c.head = (c.head + len(c.fdl) - 1) % len(c.fdl)
+	X := c.fdl[c.head]
+	copy(X, c.input)
+	fft(X)
+	// The input from p blocks ago meets kernel partition p
+	for i := range c.acc {
+		c.acc[i] = 0
+	}
+	for p, H := range c.partitions {
+		X := c.fdl[(c.head+p)%len(c.fdl)]
+		for i := range c.acc {
+			c.acc[i] += X[i] * H[i]
+		}
+	}
+	ifft(c.acc)
+	// The first half wraps around in the circular convolution, so is discarded
+	copy(c.out, c.acc[B:])
+	return c.out
+}
+
+// Reset clears the input history of the PartitionedConvolver, for a new stream.
+func (c *PartitionedConvolver) Reset() {
+	for i := range c.input {
+		c.input[i] = 0
+	}
+	for _, X := range c.fdl {
+		for i := range X {
+			X[i] = 0
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package fft
+
+import (
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestOverlapAdd(t *testing.T) {
+	// Test NewOverlapAdd of an empty kernel or non-positive block size returns InputSizeError
+	_, err := NewOverlapAdd(nil, 4)
+	checkIsInputSizeError(t, "NewOverlapAdd(nil, 4)", err)
+	_, err = NewOverlapAdd(complexRand(4), 0)
+	checkIsInputSizeError(t, "NewOverlapAdd(complexRand(4), 0)", err)
+	// Test the concatenated output over random block boundaries == slowConvolve(x, kernel)
+	for i := 0; i < 100; i++ {
+		kernel := complexRand(rand.Intn(40) + 1)
+		blockSize := rand.Intn(64) + 1
+		x := complexRand(rand.Intn(500) + 1)
+		o, err := NewOverlapAdd(kernel, blockSize)
+		if err != nil {
+			t.Fatalf("NewOverlapAdd error: %v", err)
+		}
+		var r2 []complex128
+		for s := 0; s < len(x); {
+			e := s + rand.Intn(2*blockSize+1)
+			if e > len(x) {
+				e = len(x)
+			}
+			out := o.Process(x[s:e])
+			if len(out) != e-s {
+				t.Errorf("OverlapAdd.Process output length, got: %d, expected: %d", len(out), e-s)
+			}
+			r2 = append(r2, out...)
+			s = e
+		}
+		r2 = append(r2, o.Flush()...)
+		r1 := slowConvolve(x, kernel)
+		if len(r1) != len(r2) {
+			t.Errorf("slowConvolve and OverlapAdd differ in length: len(r1)=%d, len(r2)=%d", len(r1), len(r2))
+			continue
+		}
+		for k := range r1 {
+			if e := cmplx.Abs(r1[k] - r2[k]); e > 1e-9 {
+				t.Errorf("slowConvolve and OverlapAdd differ: r1[%d]=%v, r2[%d]=%v, diff=%v", k, r1[k], k, r2[k], e)
+			}
+		}
+		// Test Flush resets the tail for a new signal
+		for _, v := range o.Flush() {
+			if v != 0 {
+				t.Errorf("OverlapAdd.Flush didn't reset tail, got: %v", v)
+			}
+		}
+	}
+}
+
+func TestOverlapSaveConvolve(t *testing.T) {
+	// Test empty inputs match Convolve
+	if y, err := OverlapSaveConvolve(nil, nil); y != nil || err != nil {
+		t.Errorf("OverlapSaveConvolve(nil, nil) = %v, %v, expected nil, nil", y, err)
+	}
+	if y, _ := OverlapSaveConvolve(nil, complexRand(4)); len(y) != 3 {
+		t.Errorf("OverlapSaveConvolve(nil, complexRand(4)) length = %d, expected 3", len(y))
+	}
+	// Test OverlapSaveConvolve(x, kernel) == slowConvolve(x, kernel) for random lengths
+	for i := 0; i < 100; i++ {
+		x := complexRand(rand.Intn(2000) + 1)
+		kernel := complexRand(rand.Intn(70) + 1)
+		if i%10 == 0 {
+			x, kernel = kernel, x
+		}
+		r1 := slowConvolve(x, kernel)
+		r2, err := OverlapSaveConvolve(x, kernel)
+		if err != nil {
+			t.Errorf("OverlapSaveConvolve error: %v", err)
+		}
+		if len(r1) != len(r2) {
+			t.Errorf("slowConvolve and OverlapSaveConvolve lengths differ: %d %d", len(r1), len(r2))
+			continue
+		}
+		for j := range r1 {
+			if e := cmplx.Abs(r1[j] - r2[j]); e > 1e-9 {
+				t.Errorf("slowConvolve and OverlapSaveConvolve differ: len(x)=%d len(kernel)=%d r1[%d]=%v r2[%d]=%v diff=%v", len(x), len(kernel), j, r1[j], j, r2[j], e)
+			}
+		}
+	}
+}
+
+func TestPartitionedConvolver(t *testing.T) {
+	// Test NewPartitionedConvolver of an empty kernel or non-power of 2 block size returns InputSizeError
+	_, err := NewPartitionedConvolver(nil, 4)
+	checkIsInputSizeError(t, "NewPartitionedConvolver(nil, 4)", err)
+	_, err = NewPartitionedConvolver(complexRand(4), 6)
+	checkIsInputSizeError(t, "NewPartitionedConvolver(complexRand(4), 6)", err)
+	// Test the concatenated output blocks, with 0 blocks for the tail, == slowConvolve(x, kernel)
+	for i := 0; i < 100; i++ {
+		kernel := complexRand(rand.Intn(100) + 1)
+		B := 1 << rand.Intn(6)
+		x := complexRand(rand.Intn(20*B) + 1)
+		c, err := NewPartitionedConvolver(kernel, B)
+		if err != nil {
+			t.Fatalf("NewPartitionedConvolver error: %v", err)
+		}
+		r1 := slowConvolve(x, kernel)
+		in := ZeroPad(x, (len(r1)+B-1)/B*B)
+		var r2 []complex128
+		for s := 0; s < len(in); s += B {
+			out := c.Process(in[s : s+B])
+			if len(out) != B {
+				t.Fatalf("PartitionedConvolver.Process returned %d samples, expected %d", len(out), B)
+			}
+			r2 = append(r2, out...)
+		}
+		for j := range r1 {
+			if e := cmplx.Abs(r1[j] - r2[j]); e > 1e-9 {
+				t.Errorf("slowConvolve and PartitionedConvolver differ: len(kernel)=%d B=%d r1[%d]=%v r2[%d]=%v diff=%v", len(kernel), B, j, r1[j], j, r2[j], e)
+			}
+		}
+		for j := len(r1); j < len(r2); j++ {
+			if e := cmplx.Abs(r2[j]); e > 1e-9 {
+				t.Errorf("PartitionedConvolver tail not 0: r2[%d]=%v", j, r2[j])
+			}
+		}
+		// Test Reset starts a new stream
+		c.Reset()
+		out := c.Process(ZeroPad(x[:1], B))
+		if e := cmplx.Abs(out[0] - x[0]*kernel[0]); e > 1e-9 {
+			t.Errorf("PartitionedConvolver after Reset differs: got=%v expected=%v diff=%v", out[0], x[0]*kernel[0], e)
+		}
+	}
+}
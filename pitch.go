@@ -0,0 +1,57 @@
+package fft
+
+import "math"
+
+// minPitchClarity is the smallest autocorrelation peak, relative to the
+// autocorrelation at lag 0, that DetectPitch accepts as periodic
+const minPitchClarity = 0.3
+
+// DetectPitch estimates the fundamental frequency in Hz of the real signal x,
+// sampled at sampleRate, from the peak of its autocorrelation.
+// The autocorrelation is computed with FFT, 0-padded internally to a power of 2,
+// after removing the mean of x. The highest local maximum among the lags
+// corresponding to frequencies in [minFreq, maxFreq] is then refined with ParabolicPeak.
+// Returns 0 if there is no clear peak, when the highest is below 0.3 of the
+// autocorrelation at lag 0, or if no lags lie in the range.
+// This does not alter x.
+// len(x) must be positive, otherwise this will return an error.
+func DetectPitch(x []float64, sampleRate, minFreq, maxFreq float64) (float64, error) {
+	if err := checkPositive("DetectPitch input length", len(x)); err != nil {
+		return 0, err
+	}
+	if maxFreq <= 0 || minFreq > maxFreq {
+		return 0, nil
+	}
+	minLag := max(1, int(math.Floor(sampleRate/maxFreq)))
+	maxLag := len(x) - 2 // leaving a neighbour for every local maximum
+	if minFreq > 0 {
+		maxLag = min(maxLag, int(math.Ceil(sampleRate/minFreq)))
+	}
+	if minLag > maxLag {
+		return 0, nil
+	}
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	y := make([]float64, len(x))
+	for i, v := range x {
+		y[i] = v - mean
+	}
+	r := autocorrelate(y)
+	if r[0] <= 0 {
+		return 0, nil
+	}
+	peak := -1
+	for lag := minLag; lag <= maxLag; lag++ {
+		if r[lag] >= r[lag-1] && r[lag] >= r[lag+1] && (peak < 0 || r[lag] > r[peak]) {
+			peak = lag
+		}
+	}
+	if peak < 0 || r[peak] < minPitchClarity*r[0] {
+		return 0, nil
+	}
+	offset, _ := ParabolicPeak(r, peak)
+	return sampleRate / (float64(peak) + offset), nil
+}
@@ -0,0 +1,47 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetectPitch(t *testing.T) {
+	// Test an empty input returns InputSizeError
+	_, err := DetectPitch(nil, 8000, 50, 1000)
+	checkIsInputSizeError(t, "DetectPitch(nil, 8000, 50, 1000)", err)
+	fs := 8000.0
+	for _, f0 := range []float64{82.4, 220, 441.7, 900} {
+		// Test a pure tone, and a tone with harmonics stronger than the fundamental
+		pure := tone(2048, f0, fs, 1)
+		rich := tone(2048, f0, fs, 0.5)
+		for h := 2.0; h <= 4; h++ {
+			for i, v := range tone(2048, h*f0, fs, 1/h+0.4) {
+				rich[i] += v
+			}
+		}
+		for name, x := range map[string][]float64{"pure": pure, "rich": rich} {
+			got, err := DetectPitch(x, fs, 50, 1000)
+			if err != nil {
+				t.Errorf("DetectPitch error: %v", err)
+			}
+			if e := math.Abs(got - f0); e > 0.01*f0 {
+				t.Errorf("DetectPitch(%s %v Hz), got: %v, diff=%v", name, f0, got, e)
+			}
+		}
+	}
+	// Test noise, constant and out of range signals have no pitch
+	for _, c := range []struct {
+		name     string
+		x        []float64
+		min, max float64
+	}{
+		{"noise", floatRand(2048), 50, 1000},
+		{"constant", tone(2048, 0, fs, 1), 50, 1000},
+		{"no lags in range", tone(64, 220, fs, 1), 50, 100},
+		{"empty range", tone(2048, 220, fs, 1), 500, 100},
+	} {
+		if got, err := DetectPitch(c.x, fs, c.min, c.max); got != 0 || err != nil {
+			t.Errorf("DetectPitch(%s), got: %v, %v, expected: 0, nil", c.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package fft
+
+// Plan is a validated transform size that can be reused across many
+// same-size transforms, skipping the power of 2 check on every call.
+// The transform keeps no global state or precomputed tables, so a Plan is
+// safe for concurrent use on different slices.
+type Plan struct {
+	n int
+}
+
+// NewPlan creates a Plan for transforms of length N.
+// N must be a perfect power of 2, otherwise this will return an error.
+func NewPlan(N int) (*Plan, error) {
+	if err := checkLength("Plan size", N); err != nil {
+		return nil, err
+	}
+	return &Plan{n: N}, nil
+}
+
+// N returns the transform length of the Plan.
+func (p *Plan) N() int {
+	return p.n
+}
+
+// FFT implements the fast Fourier transform using the Plan.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must equal p.N(), otherwise this will return an error.
+func (p *Plan) FFT(x []complex128) error {
+	if err := checkZero("difference in Plan FFT input length and Plan size", len(x)-p.n); err != nil {
+		return err
+	}
+	fft(x)
+	return nil
+}
+
+// IFFT implements the inverse fast Fourier transform using the Plan.
+// This is done in-place (modifying the input array).
+// Requires O(1) additional memory.
+// len(x) must equal p.N(), otherwise this will return an error.
+func (p *Plan) IFFT(x []complex128) error {
+	if err := checkZero("difference in Plan IFFT input length and Plan size", len(x)-p.n); err != nil {
+		return err
+	}
+	ifft(x)
+	return nil
+}
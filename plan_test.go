@@ -0,0 +1,49 @@
+package fft
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	// Test NewPlan of non-powers of 2 returns InputSizeError
+	_, err := NewPlan(17)
+	checkIsInputSizeError(t, "NewPlan(17)", err)
+	// Test Plan.FFT and Plan.IFFT of the wrong length return InputSizeError
+	p, err := NewPlan(16)
+	if err != nil {
+		t.Fatalf("NewPlan error: %v", err)
+	}
+	checkIsInputSizeError(t, "p.FFT(complexRand(8))", p.FFT(complexRand(8)))
+	checkIsInputSizeError(t, "p.IFFT(complexRand(32))", p.IFFT(complexRand(32)))
+	if p.N() != 16 {
+		t.Errorf("NewPlan(16).N(), got: %d, expected: 16", p.N())
+	}
+	// Test p.FFT(x) == slowFFT(x) and p.IFFT(p.FFT(x)) == x for power of 2 up to 2^10
+	for N := 1; N < (1 << 11); N <<= 1 {
+		p, err := NewPlan(N)
+		if err != nil {
+			t.Errorf("NewPlan error: %v", err)
+			continue
+		}
+		x := complexRand(N)
+		y1 := slowFFT(x)
+		y2 := copyVector(x)
+		if err := p.FFT(y2); err != nil {
+			t.Errorf("Plan.FFT error: %v", err)
+		}
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(y1[i] - y2[i]); e > 1e-9 {
+				t.Errorf("slowFFT and Plan.FFT differ: i=%d N=%d y1[%d]=%v y2[%d]=%v diff=%v", i, N, i, y1[i], i, y2[i], e)
+			}
+		}
+		if err := p.IFFT(y2); err != nil {
+			t.Errorf("Plan.IFFT error: %v", err)
+		}
+		for i := 0; i < N; i++ {
+			if e := cmplx.Abs(x[i] - y2[i]); e > 1e-9 {
+				t.Errorf("Plan inverse differs %d: %v %v", i, x[i], y2[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package fft
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// workerPool is a fixed set of goroutines running the tasks sent to them,
+// shared by every parallel transform, so that they don't start new goroutines
+// on each call.
+type workerPool struct {
+	tasks chan func()
+	size  int
+}
+
+var (
+	poolMu sync.RWMutex // guards pool, held for reading while sending tasks
+	pool   *workerPool  // started on first use
+)
+
+// newWorkerPool starts a workerPool of size goroutines
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{tasks: make(chan func()), size: size}
+	for i := 0; i < size; i++ {
+		go func() {
+			for f := range p.tasks {
+				f()
+			}
+		}()
+	}
+	return p
+}
+
+// SetParallelism sets the number of goroutines in the worker pool shared by
+// every parallel transform, such as FFTBatch and FastMultiConvolve with
+// multithread set. If n <= 0, runtime.NumCPU() is used, which is the default.
+// Transforms already running finish on the previous pool, whose goroutines
+// then exit.
+// This is safe for concurrent use.
+func SetParallelism(n int) {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if pool != nil {
+		close(pool.tasks)
+	}
+	pool = newWorkerPool(n)
+}
+
+// Parallelism returns the number of goroutines in the worker pool shared by
+// every parallel transform, as set by SetParallelism.
+func Parallelism() int {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	if pool == nil {
+		return runtime.NumCPU()
+	}
+	return pool.size
+}
+
+// defaultParallelThreshold is the default for SetParallelThreshold, the work of
+// a single transform of length 2^14. Handing tasks to the worker pool costs a few
+// microseconds, under 1% of ConvolveAuto at that length, so this errs on the side
+// of not using the pool (see BenchmarkConvolveAuto)
+const defaultParallelThreshold = 1 << 18
+
+// parallelThreshold is the threshold set by SetParallelThreshold, or 0 for the default
+var parallelThreshold atomic.Int64
+
+// SetParallelThreshold sets the amount of work, N*log2(N) for a transform of
+// total length N, at or above which ConvolveAuto and MultiConvolveAuto use the
+// worker pool. Below it they run on the calling goroutine, which is faster for
+// small inputs. If n <= 0, the default of 2^18 (N = 2^14) is restored.
+// The best threshold depends on the machine, so benchmark your workload to tune it.
+// This is safe for concurrent use.
+func SetParallelThreshold(n int) {
+	parallelThreshold.Store(int64(max(n, 0)))
+}
+
+// ParallelThreshold returns the threshold set by SetParallelThreshold.
+func ParallelThreshold() int {
+	if n := parallelThreshold.Load(); n > 0 {
+		return int(n)
+	}
+	return defaultParallelThreshold
+}
+
+// useParallel reports whether a transform of total length N is enough work,
+// against ParallelThreshold, to be worth spreading across the worker pool,
+// which it never is with only one goroutine in the pool
+func useParallel(N int) bool {
+	if Parallelism() < 2 {
+		return false
+	}
+	// N*log2(N), rounding log2 up for lengths that are not powers of 2
+	return N*bits.Len(uint(max(N-1, 0))) >= ParallelThreshold()
+}
+
+// parallel runs f(j) for each j in [0, tasks) on the worker pool, starting it
+// if needed, and waits for them all to return.
+// f must not itself call parallel, since it could wait forever on a full pool.
+func parallel(tasks int, f func(j int)) {
+	var wg sync.WaitGroup
+	wg.Add(tasks)
+	poolMu.RLock()
+	for pool == nil {
+		poolMu.RUnlock()
+		poolMu.Lock()
+		if pool == nil {
+			pool = newWorkerPool(runtime.NumCPU())
+		}
+		poolMu.Unlock()
+		poolMu.RLock()
+	}
+	for j := 0; j < tasks; j++ {
+		pool.tasks <- func() {
+			defer wg.Done()
+			f(j)
+		}
+	}
+	poolMu.RUnlock()
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package fft
+
+import (
+	"math/cmplx"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSetParallelism(t *testing.T) {
+	defer SetParallelism(0)
+	for _, n := range []int{1, 3, 8} {
+		SetParallelism(n)
+		if got := Parallelism(); got != n {
+			t.Errorf("Parallelism() after SetParallelism(%d), got: %d, expected: %d", n, got, n)
+		}
+	}
+	SetParallelism(-1)
+	if got := Parallelism(); got != runtime.NumCPU() {
+		t.Errorf("Parallelism() after SetParallelism(-1), got: %d, expected: %d", got, runtime.NumCPU())
+	}
+	// Test parallel transforms reuse the pool, rather than starting goroutines per call
+	x := complexRand(64 * 256)
+	FFTBatch(x, 64, true)
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		FFTBatch(x, 64, true)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("FFTBatch started goroutines: before=%d after=%d", before, after)
+	}
+	// Test FFTBatch stays correct while the pool is resized concurrently
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			x := complexRand(32 * 64)
+			y := copyVector(x)
+			if err := FFTBatch(y, 32, true); err != nil {
+				t.Errorf("FFTBatch error: %v", err)
+			}
+			SetParallelism(g + 1)
+			for i := 0; i < 64; i++ {
+				expect := slowFFT(copyVector(x[i*32 : (i+1)*32]))
+				for k := range expect {
+					if e := cmplx.Abs(expect[k] - y[i*32+k]); e > 1e-9 {
+						t.Errorf("FFTBatch with concurrent SetParallelism differs: vector=%d k=%d diff=%v", i, k, e)
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestSetParallelThreshold(t *testing.T) {
+	defer SetParallelThreshold(0)
+	defer SetParallelism(0)
+	if got := ParallelThreshold(); got != defaultParallelThreshold {
+		t.Errorf("ParallelThreshold() by default, got: %d, expected: %d", got, defaultParallelThreshold)
+	}
+	SetParallelThreshold(1000)
+	if got := ParallelThreshold(); got != 1000 {
+		t.Errorf("ParallelThreshold() after SetParallelThreshold(1000), got: %d, expected: 1000", got)
+	}
+	// Test the work of each length against the threshold, N*log2(N)
+	SetParallelism(4)
+	for _, c := range []struct {
+		N      int
+		expect bool
+	}{{0, false}, {1, false}, {64, false}, {128, false}, {129, true}, {256, true}} {
+		if got := useParallel(c.N); got != c.expect {
+			t.Errorf("useParallel(%d) with a threshold of 1000, got: %t, expected: %t", c.N, got, c.expect)
+		}
+	}
+	// Test a single goroutine never runs in parallel
+	SetParallelism(1)
+	if useParallel(1 << 20) {
+		t.Errorf("useParallel(1 << 20) with Parallelism() == 1, got: true, expected: false")
+	}
+	SetParallelThreshold(-5)
+	if got := ParallelThreshold(); got != defaultParallelThreshold {
+		t.Errorf("ParallelThreshold() after SetParallelThreshold(-5), got: %d, expected: %d", got, defaultParallelThreshold)
+	}
+}
@@ -0,0 +1,92 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// PrunedFFT computes only the first outputBins bins of the FFT of x, such as
+// for a coarse low-frequency feature, returning them in a new array.
+// The decimation-in-time butterflies are pruned so that each stage past
+// length outputBins computes only the outputs that the first outputBins bins
+// depend on, reducing the work from O(N log N) to O(N log outputBins + N).
+// As the permutation and the first log2(outputBins) steps still cost as much as
+// in FFT, this only pays off for small outputBins: measured at N = 65536, it is no
+// faster than copying x and using FFT at outputBins = N/8, around 10% faster at N/16,
+// 25% faster at N/64, and 2x faster at N/16384.
+// This does not alter x.
+// len(x) and outputBins must be perfect powers of 2, with outputBins at most len(x),
+// otherwise this will return an error.
+func PrunedFFT(x []complex128, outputBins int) ([]complex128, error) {
+	if err := checkLength("PrunedFFT Input", len(x)); err != nil {
+		return nil, err
+	}
+	if err := checkLength("PrunedFFT output bins", outputBins); err != nil {
+		return nil, err
+	}
+	if outputBins > len(x) {
+		return nil, &InputSizeError{Context: "PrunedFFT output bins", Requirement: fmt.Sprintf("at most the input length %d", len(x)), Size: outputBins, Err: ErrOutOfRange}
+	}
+	y := ZeroPad(x, len(x))
+	permuteGeneric(y)
+	prunedFFT(y, outputBins)
+	return y[:outputBins:outputBins], nil
+}
+
+// prunedFFT does the actual work for PrunedFFT on x, which must already be
+// permuted into bit-reversed order, leaving the first M bins of the FFT in x[:M],
+// and the rest of x in an unspecified state.
+func prunedFFT(x []complex128, M int) {
+	N := len(x)
+	// The first log2(M) steps are a full FFT of each block of M
+	for o := 0; o < N; o += M {
+		butterflyGeneric(x[o : o+M])
+	}
+	if M == N {
+		return
+	}
+	// The remaining steps only need the first M outputs of each group.
+	// Their twiddles only depend on k < M, so are computed once per step into tw,
+	// rather than once per group.
+	tw := make([]complex128, 3*M)
+	tw1, tw2, tw3 := tw[:M], tw[M:2*M], tw[2*M:]
+	n := M
+	// A single radix-2 step if there is an odd number of them
+	if bits.Len(uint(N/M))%2 == 0 {
+		prunedTwiddles(tw1, -math.Pi/float64(n))
+		for o := 0; o < N; o += (n << 1) {
+			for k := 0; k < M; k++ {
+				i := k + o
+				x[i] += tw1[k] * x[i+n]
+			}
+		}
+		n <<= 1
+	}
+	// Then radix-4 steps, which like radix4Generic combine the 4 transforms of the
+	// inputs congruent to 0, 2, 1 and 3 mod 4, but only into the first quarter
+	for ; n < N; n <<= 2 {
+		prunedTwiddles(tw1, -math.Pi/float64(2*n))
+		for k, w := range tw1 {
+			tw2[k] = w * w
+			tw3[k] = tw2[k] * w
+		}
+		for o := 0; o < N; o += (n << 2) {
+			for k := 0; k < M; k++ {
+				i := k + o
+				x[i] += tw1[k]*x[i+2*n] + tw2[k]*x[i+n] + tw3[k]*x[i+3*n]
+			}
+		}
+	}
+}
+
+// prunedTwiddles fills tw with exp(i*theta*k) for each k
+func prunedTwiddles(tw []complex128, theta float64) {
+	s, c := math.Sincos(theta)
+	w := complex(c, s)
+	wj := complex(1, 0)
+	for k := range tw {
+		tw[k] = wj
+		wj *= w
+	}
+}
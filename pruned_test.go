@@ -0,0 +1,50 @@
+package fft
+
+import (
+	"fmt"
+	"math/cmplx"
+	"testing"
+)
+
+func TestPrunedFFT(t *testing.T) {
+	// Test invalid lengths return InputSizeError
+	_, err := PrunedFFT(complexRand(17), 4)
+	checkIsInputSizeError(t, "PrunedFFT(complexRand(17), 4)", err)
+	_, err = PrunedFFT(complexRand(16), 3)
+	checkIsInputSizeError(t, "PrunedFFT(complexRand(16), 3)", err)
+	_, err = PrunedFFT(complexRand(16), 32)
+	checkIsInputSizeError(t, "PrunedFFT(complexRand(16), 32)", err)
+	// Test PrunedFFT(x, M) == slowFFT(x)[:M] for every valid M
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := complexRand(N)
+		y1 := slowFFT(copyVector(x))
+		for M := 1; M <= N; M <<= 1 {
+			y2, err := PrunedFFT(x, M)
+			if err != nil {
+				t.Errorf("PrunedFFT error: %v", err)
+			}
+			if len(y2) != M {
+				t.Errorf("PrunedFFT length differs: N=%d M=%d got=%d", N, M, len(y2))
+				continue
+			}
+			for i := range y2 {
+				if e := cmplx.Abs(y1[i] - y2[i]); e > 1e-9 {
+					t.Errorf("slowFFT and PrunedFFT differ: N=%d M=%d i=%d y1=%v y2=%v diff=%v", N, M, i, y1[i], y2[i], e)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkPrunedFFT(b *testing.B) {
+	N := 1 << 16
+	x := complexRand(N)
+	for _, M := range []int{N, N / 8, N / 64} {
+		b.Run(fmt.Sprintf("%d/%d", M, N), func(b *testing.B) {
+			b.SetBytes(int64(N * 16))
+			for i := 0; i < b.N; i++ {
+				PrunedFFT(x, M)
+			}
+		})
+	}
+}
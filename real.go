@@ -0,0 +1,123 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// RFFTMagnitude computes the magnitudes of the FFTs of many real frames,
+// such as for feature extraction, with the window applied to each frame first.
+// Each result has the len(frame)/2+1 non-redundant bins, up to and including Nyquist.
+// Each frame is transformed with a complex FFT of half its length, on a single
+// reused scratch buffer, and all of the results share one backing array.
+// This does not alter frames. Returns nil if frames is empty.
+// Every frame must have the same length, a perfect power of 2, otherwise this will return an error.
+func RFFTMagnitude(frames [][]float64, window Window) ([][]float64, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	N := len(frames[0])
+	if err := checkLength("RFFTMagnitude frame length", N); err != nil {
+		return nil, err
+	}
+	for i, frame := range frames {
+		if err := checkZero(fmt.Sprintf("difference in RFFTMagnitude frame %d length and frame 0 length", i), len(frame)-N); err != nil {
+			return nil, err
+		}
+	}
+	weights := windowWeights(window, N)
+	twiddles := rfftTwiddles(N)
+	bins := N/2 + 1
+	data := make([]float64, len(frames)*bins)
+	z := make([]complex128, N/2)
+	mags := make([][]float64, len(frames))
+	for i, frame := range frames {
+		mags[i] = data[i*bins : (i+1)*bins : (i+1)*bins]
+		rfftMagnitude(frame, weights, twiddles, z, mags[i])
+	}
+	return mags, nil
+}
+
+// rfftMagnitude does the actual work for RFFTMagnitude on a single frame x,
+// packing the even samples into the real part and the odd samples into the
+// imaginary part of the scratch buffer z, of length len(x)/2, and writing
+// the len(x)/2+1 magnitudes into mag. twiddles must be rfftTwiddles(len(x)),
+// so that they are shared across frames.
+func rfftMagnitude(x, weights []float64, twiddles, z []complex128, mag []float64) {
+	N := len(x)
+	if N == 1 {
+		mag[0] = math.Abs(x[0] * weights[0])
+		return
+	}
+	M := N / 2
+	for m := range z {
+		z[m] = complex(x[2*m]*weights[2*m], x[2*m+1]*weights[2*m+1])
+	}
+	fft(z)
+	for k := 0; k <= M; k++ {
+		// E[k] = (Z[k]+conj(Z[M-k]))/2, O[k] = (Z[k]-conj(Z[M-k]))/2i, X[k] = E[k] + W^k*O[k]
+		a, b := z[k%M], cmplx.Conj(z[(M-k)%M])
+		mag[k] = cmplx.Abs((a+b)/2 + twiddles[k]*(a-b)/complex(0, 2))
+	}
+}
+
+// rfftTwiddles returns exp(-2*Pi*i*k/N) for k in [0, N/2], as rfftMagnitude
+// needs for combining the even and odd halves of a frame of length N.
+func rfftTwiddles(N int) []complex128 {
+	w := make([]complex128, N/2+1)
+	for k := range w {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(N))
+		w[k] = complex(c, s)
+	}
+	return w
+}
+
+// IRFFTFromHalf computes the real inverse FFT of length n from half, the n/2+1
+// non-redundant bins of a conjugate-symmetric spectrum, up to and including Nyquist,
+// such as the spectrum of a real signal with its redundant upper half dropped.
+// The upper half is implied by conjugate-symmetry, X[n-k] = conj(X[k]), and the
+// imaginary parts of the DC and Nyquist bins are ignored.
+// This uses a single complex IFFT of length n/2, doing about half the work of IFFT.
+// This does not alter half, and returns a new array.
+// n must be a perfect power of 2, and len(half) must equal n/2+1, otherwise this will return an error.
+func IRFFTFromHalf(half []complex128, n int) ([]float64, error) {
+	if err := checkLength("IRFFTFromHalf output length", n); err != nil {
+		return nil, err
+	}
+	if err := checkZero("difference in IRFFTFromHalf input length and n/2+1", len(half)-(n/2+1)); err != nil {
+		return nil, err
+	}
+	if n == 1 {
+		return []float64{real(half[0])}, nil
+	}
+	// Drop the imaginary parts of DC and Nyquist, which a real signal can't have
+	h := ZeroPad(half, len(half))
+	h[0] = complex(real(h[0]), 0)
+	h[n/2] = complex(real(h[n/2]), 0)
+	return irfftHalf(h, n), nil
+}
+
+// irfftHalf computes the real inverse FFT of length N from the N/2+1
+// non-redundant bins of a conjugate-symmetric spectrum, using a single complex
+// IFFT of length N/2 by packing the even samples into the real part and the
+// odd samples into the imaginary part.
+// N must be a power of 2 of at least 2, and len(half) must be at least N/2+1.
+func irfftHalf(half []complex128, N int) []float64 {
+	M := N / 2
+	q := make([]complex128, M)
+	for k := 0; k < M; k++ {
+		// P[k+N/2] = conj(P[N/2-k]) by conjugate-symmetry
+		a, b := half[k], cmplx.Conj(half[M-k])
+		s, c := math.Sincos(2 * math.Pi * float64(k) / float64(N))
+		// E[k] = (P[k]+P[k+N/2])/2, O[k] = (P[k]-P[k+N/2])/(2*W^k), Q[k] = E[k] + i*O[k]
+		q[k] = (a + b + complex(0, 1)*(a-b)*complex(c, s)) / 2
+	}
+	ifft(q)
+	y := make([]float64, N)
+	for m, v := range q {
+		y[2*m] = real(v)
+		y[2*m+1] = imag(v)
+	}
+	return y
+}
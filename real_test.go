@@ -0,0 +1,91 @@
+package fft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRFFTMagnitude(t *testing.T) {
+	// Test non-power of 2 and mismatched frame lengths return InputSizeError
+	_, err := RFFTMagnitude([][]float64{floatRand(12)}, Hanning)
+	checkIsInputSizeError(t, "RFFTMagnitude([][]float64{floatRand(12)}, Hanning)", err)
+	_, err = RFFTMagnitude([][]float64{floatRand(16), floatRand(8)}, Hanning)
+	checkIsInputSizeError(t, "RFFTMagnitude([][]float64{floatRand(16), floatRand(8)}, Hanning)", err)
+	if mags, err := RFFTMagnitude(nil, Hanning); mags != nil || err != nil {
+		t.Errorf("RFFTMagnitude(nil) = %v, %v, expected nil, nil", mags, err)
+	}
+	// Test RFFTMagnitude matches Magnitude of the FFT of each windowed frame
+	for N := 1; N < (1 << 11); N <<= 1 {
+		for _, window := range []Window{Rectangular, Hanning | Periodic, Kaiser} {
+			frames := [][]float64{floatRand(N), floatRand(N), floatRand(N)}
+			mags, err := RFFTMagnitude(frames, window)
+			if err != nil {
+				t.Errorf("RFFTMagnitude error: %v", err)
+			}
+			for i, frame := range frames {
+				x := ApplyWindow(Float64ToComplex128Array(frame), window)
+				fft(x)
+				expected := Magnitude(x)[:N/2+1]
+				if len(mags[i]) != len(expected) {
+					t.Errorf("RFFTMagnitude length differs: N=%d got=%d expected=%d", N, len(mags[i]), len(expected))
+					continue
+				}
+				for k := range expected {
+					if e := math.Abs(mags[i][k] - expected[k]); e > 1e-9 {
+						t.Errorf("RFFTMagnitude differs: N=%d window=%d frame=%d k=%d got=%v expected=%v diff=%v", N, window, i, k, mags[i][k], expected[k], e)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIRFFTFromHalf(t *testing.T) {
+	// Test non-power of 2 and mismatched lengths return InputSizeError
+	_, err := IRFFTFromHalf(complexRand(7), 12)
+	checkIsInputSizeError(t, "IRFFTFromHalf(complexRand(7), 12)", err)
+	_, err = IRFFTFromHalf(complexRand(8), 16)
+	checkIsInputSizeError(t, "IRFFTFromHalf(complexRand(8), 16)", err)
+	// Test IRFFTFromHalf of the lower half of the FFT of real x gives back x
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := floatRand(N)
+		X := Float64ToComplex128Array(x)
+		fft(X)
+		half := copyVector(X[:N/2+1])
+		y, err := IRFFTFromHalf(half, N)
+		if err != nil {
+			t.Errorf("IRFFTFromHalf error: %v", err)
+		}
+		for i := range x {
+			if e := math.Abs(x[i] - y[i]); e > 1e-9 {
+				t.Errorf("IRFFTFromHalf differs: N=%d i=%d got=%v expected=%v diff=%v", N, i, y[i], x[i], e)
+			}
+		}
+		// Test half is not altered, and imaginary parts of DC and Nyquist are ignored
+		for k := range half {
+			if half[k] != X[k] {
+				t.Errorf("IRFFTFromHalf altered its input: N=%d k=%d", N, k)
+			}
+		}
+		half[0] += 1i
+		half[N/2] += 2i
+		y, _ = IRFFTFromHalf(half, N)
+		for i := range x {
+			if e := math.Abs(x[i] - y[i]); e > 1e-9 {
+				t.Errorf("IRFFTFromHalf with imaginary DC and Nyquist differs: N=%d i=%d got=%v expected=%v diff=%v", N, i, y[i], x[i], e)
+			}
+		}
+	}
+}
+
+func BenchmarkRFFTMagnitude(b *testing.B) {
+	frames := make([][]float64, 1000)
+	for i := range frames {
+		frames[i] = floatRand(512)
+	}
+	b.SetBytes(int64(len(frames) * 512 * 8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RFFTMagnitude(frames, Hanning|Periodic)
+	}
+}
@@ -0,0 +1,161 @@
+package fft
+
+// Resample resamples x to targetLen samples by zero-padding (upsampling) or
+// truncating (downsampling) its spectrum at the high frequencies, matching
+// scipy.signal.resample. On upsampling the Nyquist bin is split evenly between
+// the positive and negative halves, and on downsampling the two bins folded onto
+// the new Nyquist bin are summed.
+// The signal is assumed to be periodic, so discontinuities between the end and
+// start of x will ring.
+// This does not alter x, and returns a new array.
+// len(x) and targetLen must be perfect powers of 2, otherwise this will return an error.
+func Resample(x []complex128, targetLen int) ([]complex128, error) {
+	if err := checkLength("Resample Input", len(x)); err != nil {
+		return nil, err
+	}
+	if err := checkLength("Resample target length", targetLen); err != nil {
+		return nil, err
+	}
+	N, M := len(x), targetLen
+	X := make([]complex128, N)
+	copy(X, x)
+	if N == M {
+		return X, nil
+	}
+	fft(X)
+	Y := make([]complex128, M)
+	n := min(N, M) // length of the shared spectrum
+	for k := 0; k < (n+1)/2; k++ {
+		Y[k] = X[k]
+	}
+	for k := 1; k < (n+1)/2; k++ {
+		Y[M-k] = X[N-k]
+	}
+	if n > 1 {
+		if M > N {
+			Y[n/2] = X[n/2] / 2
+			Y[M-n/2] = X[n/2] / 2
+		} else {
+			Y[n/2] = X[n/2] + X[N-n/2]
+		}
+	}
+	ifft(Y)
+	scale(Y, float64(M)/float64(N))
+	return Y, nil
+}
+
+// Resampler resamples a long (or unbounded) real signal by the rational factor
+// l/m, one block at a time, with polyphase filtering: the signal is upsampled
+// by l (inserting l-1 0s after each sample), filtered with kernel, and then
+// downsampled by m (keeping every m-th sample), like scipy.signal.upfirdn.
+// Only the kernel taps that meet nonzero samples are used: kernel is split into
+// l phases, kernel[p], kernel[p+l], kernel[p+2*l], ..., and each output sample
+// is the output of a single phase filter, computed with ConvolveReal. The last
+// few input samples are carried over into the next call to Process.
+//
+// The kernel is applied as given, so for unity gain it should sum to l, such as
+// SincLowpass(1/float64(max(l, m)), numTaps, window) scaled by l, which also
+// removes the images from upsampling and the aliases from downsampling.
+//
+// Concatenating the outputs of every call to Process, followed by Flush, gives
+// ((len(x)-1)*l+len(kernel)-1)/m+1 samples, the same result as upsampling,
+// convolving and downsampling the entire signal x.
+//
+// A Resampler is not safe for concurrent use.
+type Resampler struct {
+	l, m     int
+	length   int         // len(kernel)
+	phases   [][]float64 // the polyphase decomposition of the kernel
+	history  []float64   // the last taps-1 input samples
+	received int         // number of input samples so far
+	emitted  int         // number of output samples so far
+}
+
+// NewResampler creates a Resampler for resampling by the factor l/m, filtering
+// with kernel at the upsampled rate.
+// l and m must be positive and kernel must be non-empty, otherwise this will return an error.
+func NewResampler(l, m int, kernel []float64) (*Resampler, error) {
+	if err := checkPositive("Resampler upsampling factor", l); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("Resampler downsampling factor", m); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("Resampler kernel length", len(kernel)); err != nil {
+		return nil, err
+	}
+	taps := (len(kernel) + l - 1) / l
+	r := &Resampler{
+		l:       l,
+		m:       m,
+		length:  len(kernel),
+		phases:  make([][]float64, l),
+		history: make([]float64, taps-1),
+	}
+	for p := range r.phases {
+		for k := p; k < len(kernel); k += l {
+			r.phases[p] = append(r.phases[p], kernel[k])
+		}
+	}
+	return r, nil
+}
+
+// Process resamples the next block of the signal, returning every output sample
+// that only depends on the input so far. Over every call, this is the output
+// samples at up to min(n*l-1, (n-1)*l+len(kernel)-1) in the upsampled signal,
+// where n is the total number of input samples.
+// in may be of any length. This does not alter in.
+func (r *Resampler) Process(in []float64) []float64 {
+	n := r.received + len(in)
+	if n == 0 {
+		return []float64{}
+	}
+	return r.process(in, min(n*r.l-1, (n-1)*r.l+r.length-1)/r.m+1)
+}
+
+// Flush returns the remaining output samples, from the tail of the kernel
+// filtering past the end of the input, and resets the Resampler for a new signal.
+func (r *Resampler) Flush() []float64 {
+	var out []float64
+	if r.received > 0 {
+		end := ((r.received-1)*r.l+r.length-1)/r.m + 1
+		// The last output sample depends on the input up to this index, taken to be 0
+		last := (end - 1) * r.m / r.l
+		out = r.process(make([]float64, max(last+1-r.received, 0)), end)
+	}
+	for i := range r.history {
+		r.history[i] = 0
+	}
+	r.received = 0
+	r.emitted = 0
+	return out
+}
+
+// process does the actual work for Process and Flush, appending in to the
+// input, and returning the output samples up to index end.
+// Output sample j is at t = j*m in the upsampled signal, where it is the output of
+// phase filter t mod l at input sample t/l.
+func (r *Resampler) process(in []float64, end int) []float64 {
+	buf := make([]float64, len(r.history)+len(in))
+	copy(buf, r.history)
+	copy(buf[len(r.history):], in)
+	// buf[i] is input sample base+i
+	base := r.received - len(r.history)
+	filtered := make([][]float64, r.l)
+	out := make([]float64, max(end-r.emitted, 0))
+	for i := range out {
+		t := (r.emitted + i) * r.m
+		p := t % r.l
+		if len(r.phases[p]) == 0 {
+			continue
+		}
+		if filtered[p] == nil {
+			filtered[p], _ = ConvolveReal(buf, r.phases[p])
+		}
+		out[i] = filtered[p][t/r.l-base]
+	}
+	copy(r.history, buf[len(buf)-len(r.history):])
+	r.received += len(in)
+	r.emitted += len(out)
+	return out
+}
@@ -0,0 +1,134 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	// Test Resample of non-powers of 2 returns InputSizeError
+	_, err := Resample(complexRand(17), 32)
+	checkIsInputSizeError(t, "Resample(complexRand(17), 32)", err)
+	_, err = Resample(complexRand(16), 17)
+	checkIsInputSizeError(t, "Resample(complexRand(16), 17)", err)
+	// Test resampling a sinusoid preserves its frequency and amplitude
+	for _, N := range []int{8, 16, 64, 256} {
+		for _, M := range []int{8, 16, 64, 256} {
+			x := make([]complex128, N)
+			for i := range x {
+				x[i] = complex(2*math.Cos(2*math.Pi*3*float64(i)/float64(N)+0.5), 0)
+			}
+			y, err := Resample(x, M)
+			if err != nil {
+				t.Errorf("Resample error: %v", err)
+			}
+			if len(y) != M {
+				t.Errorf("Resample length, got: %d, expected: %d", len(y), M)
+				continue
+			}
+			for i := range y {
+				expect := complex(2*math.Cos(2*math.Pi*3*float64(i)/float64(M)+0.5), 0)
+				if e := cmplx.Abs(expect - y[i]); e > 1e-9 {
+					t.Errorf("Resample(cos, %d -> %d) differs: y[%d]=%v expected=%v diff=%v", N, M, i, y[i], expect, e)
+				}
+			}
+		}
+	}
+	// Test downsampling an upsampled signal recovers it, including the Nyquist bin
+	for N := 1; N < (1 << 8); N <<= 1 {
+		x := complexRand(N)
+		y, _ := Resample(x, 4*N)
+		z, _ := Resample(y, N)
+		for i := range x {
+			if e := cmplx.Abs(x[i] - z[i]); e > 1e-9 {
+				t.Errorf("Resample round trip differs: N=%d x[%d]=%v z[%d]=%v diff=%v", N, i, x[i], i, z[i], e)
+			}
+		}
+	}
+}
+
+// slowUpFirDn upsamples x by l, convolves with h, and downsamples by m directly
+func slowUpFirDn(x, h []float64, l, m int) []float64 {
+	up := make([]complex128, (len(x)-1)*l+1)
+	for i, v := range x {
+		up[i*l] = complex(v, 0)
+	}
+	full := slowConvolve(up, Float64ToComplex128Array(h))
+	var r []float64
+	for j := 0; j < len(full); j += m {
+		r = append(r, real(full[j]))
+	}
+	return r
+}
+
+func TestResampler(t *testing.T) {
+	// Test NewResampler of non-positive factors or an empty kernel returns InputSizeError
+	_, err := NewResampler(0, 2, floatRand(4))
+	checkIsInputSizeError(t, "NewResampler(0, 2, floatRand(4))", err)
+	_, err = NewResampler(3, 0, floatRand(4))
+	checkIsInputSizeError(t, "NewResampler(3, 0, floatRand(4))", err)
+	_, err = NewResampler(3, 2, nil)
+	checkIsInputSizeError(t, "NewResampler(3, 2, nil)", err)
+	// Test the concatenated output over random block boundaries == slowUpFirDn(x, kernel, l, m)
+	for i := 0; i < 200; i++ {
+		l, m := rand.Intn(6)+1, rand.Intn(6)+1
+		kernel := floatRand(rand.Intn(30) + 1)
+		x := floatRand(rand.Intn(200) + 1)
+		r, err := NewResampler(l, m, kernel)
+		if err != nil {
+			t.Fatalf("NewResampler error: %v", err)
+		}
+		var r2 []float64
+		for s := 0; s < len(x); {
+			e := min(s+rand.Intn(40), len(x))
+			r2 = append(r2, r.Process(x[s:e])...)
+			// Test Process returns every output sample depending only on the input so far
+			if expect := min(e*l-1, (e-1)*l+len(kernel)-1)/m + 1; e > 0 && len(r2) != expect {
+				t.Errorf("Resampler.Process output length, l=%d m=%d n=%d, got: %d, expected: %d", l, m, e, len(r2), expect)
+			}
+			s = e
+		}
+		r2 = append(r2, r.Flush()...)
+		r1 := slowUpFirDn(x, kernel, l, m)
+		if len(r1) != len(r2) {
+			t.Errorf("slowUpFirDn and Resampler differ in length, l=%d m=%d: len(r1)=%d, len(r2)=%d", l, m, len(r1), len(r2))
+			continue
+		}
+		for k := range r1 {
+			if e := math.Abs(r1[k] - r2[k]); e > 1e-9 {
+				t.Errorf("slowUpFirDn and Resampler differ, l=%d m=%d: r1[%d]=%v, r2[%d]=%v, diff=%v", l, m, k, r1[k], k, r2[k], e)
+			}
+		}
+		// Test Flush resets the Resampler for a new signal
+		if out := r.Flush(); len(out) != 0 {
+			t.Errorf("Resampler.Flush after Flush, got: %v, expected: []", out)
+		}
+		r3 := append(r.Process(x), r.Flush()...)
+		for k := range r1 {
+			if e := math.Abs(r1[k] - r3[k]); e > 1e-9 {
+				t.Errorf("Resampler differs after Flush, l=%d m=%d: r1[%d]=%v, r3[%d]=%v, diff=%v", l, m, k, r1[k], k, r3[k], e)
+			}
+		}
+	}
+	// Test resampling a tone by 3/2 with a scaled lowpass preserves its frequency and amplitude
+	l, m := 3, 2
+	kernel := SincLowpass(1/float64(max(l, m)), 121, Blackman)
+	for i := range kernel {
+		kernel[i] *= float64(l)
+	}
+	r, err := NewResampler(l, m, kernel)
+	if err != nil {
+		t.Fatalf("NewResampler error: %v", err)
+	}
+	x := tone(3000, 440, 8000, 1)
+	y := r.Process(x)
+	// The kernel delays by 60 samples at the upsampled rate, 30 at the new one
+	expect := tone(len(y), 440, 12000, 1)
+	for k := 200; k < len(y); k++ {
+		if e := math.Abs(y[k] - expect[k-30]); e > 1e-3 {
+			t.Errorf("Resampler of a tone differs: y[%d]=%v, expected=%v, diff=%v", k, y[k], expect[k-30], e)
+		}
+	}
+}
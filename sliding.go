@@ -0,0 +1,57 @@
+package fft
+
+import (
+	"math"
+)
+
+// SlidingDFT maintains the DFT of the last n samples of a signal, updating
+// every bin in O(n) as each new sample arrives instead of recomputing a full FFT,
+// using the recurrence X[k] = (X[k] + new - old) * exp(2*Pi*i*k/n).
+//
+// Rounding error in the twiddle factors accumulates with every update, so the
+// bins slowly drift from the true DFT. Call Reset periodically with the
+// current window of samples to recompute the bins exactly.
+//
+// A SlidingDFT is not safe for concurrent use.
+type SlidingDFT struct {
+	bins     []complex128
+	twiddles []complex128
+}
+
+// NewSlidingDFT creates a SlidingDFT over windows of n samples, starting from all 0 samples.
+// n must be a perfect power of 2, otherwise this will return an error.
+func NewSlidingDFT(n int) (*SlidingDFT, error) {
+	if err := checkLength("SlidingDFT size", n); err != nil {
+		return nil, err
+	}
+	twiddles := make([]complex128, n)
+	for k := range twiddles {
+		s, c := math.Sincos(2 * math.Pi * float64(k) / float64(n))
+		twiddles[k] = complex(c, s)
+	}
+	return &SlidingDFT{bins: make([]complex128, n), twiddles: twiddles}, nil
+}
+
+// Update slides the window forward by one sample, adding newSample and removing
+// oldSample, the sample from n updates ago (or 0 if there were fewer than n updates).
+// Returns the DFT of the new window, which is reused by the next call to Update or Reset.
+func (s *SlidingDFT) Update(newSample, oldSample complex128) []complex128 {
+	d := newSample - oldSample
+	for k := range s.bins {
+		s.bins[k] = (s.bins[k] + d) * s.twiddles[k]
+	}
+	return s.bins
+}
+
+// Reset recomputes the bins exactly from window, the last n samples with the
+// oldest first, using FFT. This clears any accumulated drift.
+// This does not alter window.
+// len(window) must equal n, otherwise this will return an error.
+func (s *SlidingDFT) Reset(window []complex128) error {
+	if err := checkZero("difference in SlidingDFT Reset input length and size", len(window)-len(s.bins)); err != nil {
+		return err
+	}
+	copy(s.bins, window)
+	fft(s.bins)
+	return nil
+}
@@ -0,0 +1,51 @@
+package fft
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestSlidingDFT(t *testing.T) {
+	// Test NewSlidingDFT of non-powers of 2 returns InputSizeError
+	_, err := NewSlidingDFT(17)
+	checkIsInputSizeError(t, "NewSlidingDFT(17)", err)
+	for n := 1; n < (1 << 8); n <<= 1 {
+		s, err := NewSlidingDFT(n)
+		if err != nil {
+			t.Fatalf("NewSlidingDFT error: %v", err)
+		}
+		checkIsInputSizeError(t, "SlidingDFT.Reset(complexRand(n+1))", s.Reset(complexRand(n+1)))
+		// Test after each update the bins == slowFFT of the last n samples
+		x := complexRand(3 * n)
+		for i := range x {
+			var old complex128
+			if i >= n {
+				old = x[i-n]
+			}
+			bins := s.Update(x[i], old)
+			window := make([]complex128, n)
+			for j := range window {
+				if i-n+1+j >= 0 {
+					window[j] = x[i-n+1+j]
+				}
+			}
+			expect := slowFFT(window)
+			for k := range expect {
+				if e := cmplx.Abs(expect[k] - bins[k]); e > 1e-9 {
+					t.Errorf("SlidingDFT and slowFFT differ: n=%d i=%d k=%d got=%v expected=%v diff=%v", n, i, k, bins[k], expect[k], e)
+				}
+			}
+		}
+		// Test Reset restores the exact FFT of a window
+		window := complexRand(n)
+		if err := s.Reset(window); err != nil {
+			t.Errorf("SlidingDFT.Reset error: %v", err)
+		}
+		expect := slowFFT(window)
+		for k := range expect {
+			if e := cmplx.Abs(expect[k] - s.bins[k]); e > 1e-9 {
+				t.Errorf("SlidingDFT.Reset and slowFFT differ: n=%d k=%d got=%v expected=%v diff=%v", n, k, s.bins[k], expect[k], e)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Magnitude returns the magnitude |x[i]| of each entry in x, in a new array.
+func Magnitude(x []complex128) []float64 {
+	y := make([]float64, len(x))
+	for i, v := range x {
+		y[i] = cmplx.Abs(v)
+	}
+	return y
+}
+
+// Phase returns the phase angle of each entry in x, in the range [-Pi, Pi], in a new array.
+func Phase(x []complex128) []float64 {
+	y := make([]float64, len(x))
+	for i, v := range x {
+		y[i] = cmplx.Phase(v)
+	}
+	return y
+}
+
+// Energy returns the sum of the squared magnitudes of the entries in x.
+// By Parseval's theorem, Energy(x) equals Energy(X)/len(x), where X is the FFT of x.
+func Energy(x []complex128) float64 {
+	e := 0.0
+	for _, v := range x {
+		e += real(v)*real(v) + imag(v)*imag(v)
+	}
+	return e
+}
+
+// UnwrapPhase removes the discontinuities from phase, such as the output of Phase,
+// by replacing each jump of at least Pi between consecutive entries with its
+// equivalent within [-Pi, Pi], by adding multiples of 2*Pi, as numpy.unwrap does.
+// This does not alter phase, and returns a new array.
+func UnwrapPhase(phase []float64) []float64 {
+	return unwrapPhase(phase, math.Pi)
+}
+
+// UnwrapPhaseThreshold removes the discontinuities from phase like UnwrapPhase,
+// but only corrects jumps of at least discont. As with numpy.unwrap, a discont
+// below Pi acts as Pi.
+// This does not alter phase, and returns a new array.
+func UnwrapPhaseThreshold(phase []float64, discont float64) []float64 {
+	return unwrapPhase(phase, math.Max(discont, math.Pi))
+}
+
+// GroupDelay returns the group delay in seconds at each bin of x, the frequency
+// response of a filter, such as the FFT of its impulse response sampled at sampleRate.
+// The group delay is the negative derivative of the unwrapped phase with respect
+// to angular frequency, with bins 2*Pi*sampleRate/len(x) radians per second apart.
+// It is estimated with central differences, and one-sided differences at the first and last bins.
+// Returns a new array of len(x) delays, all 0 if len(x) < 2.
+func GroupDelay(x []complex128, sampleRate float64) []float64 {
+	N := len(x)
+	delay := make([]float64, N)
+	if N < 2 {
+		return delay
+	}
+	phase := UnwrapPhase(Phase(x))
+	dw := 2 * math.Pi * sampleRate / float64(N)
+	delay[0] = -(phase[1] - phase[0]) / dw
+	for k := 1; k < N-1; k++ {
+		delay[k] = -(phase[k+1] - phase[k-1]) / (2 * dw)
+	}
+	delay[N-1] = -(phase[N-1] - phase[N-2]) / dw
+	return delay
+}
+
+// unwrapPhase does the actual work for UnwrapPhase and UnwrapPhaseThreshold
+func unwrapPhase(phase []float64, discont float64) []float64 {
+	y := make([]float64, len(phase))
+	if len(phase) == 0 {
+		return y
+	}
+	y[0] = phase[0]
+	offset := 0.0
+	for i := 1; i < len(phase); i++ {
+		d := phase[i] - phase[i-1]
+		if math.Abs(d) >= discont {
+			// Wrap d into [-Pi, Pi), keeping Pi rather than -Pi for positive d
+			w := math.Mod(d+math.Pi, 2*math.Pi)
+			if w < 0 {
+				w += 2 * math.Pi
+			}
+			w -= math.Pi
+			if w == -math.Pi && d > 0 {
+				w = math.Pi
+			}
+			offset += w - d
+		}
+		y[i] = phase[i] + offset
+	}
+	return y
+}
+
+// MagnitudeDB returns the magnitude of each entry in x in decibels relative to ref,
+// 20*log10(|x[i]|/ref), in a new array.
+// Values below floorDB (including the -Inf from a 0 magnitude) are clamped to floorDB.
+func MagnitudeDB(x []complex128, ref, floorDB float64) []float64 {
+	y := make([]float64, len(x))
+	for i, v := range x {
+		y[i] = toDB(20*math.Log10(cmplx.Abs(v)/ref), floorDB)
+	}
+	return y
+}
+
+// toDB clamps db to at least floorDB, replacing NaN with floorDB
+func toDB(db, floorDB float64) float64 {
+	if !(db >= floorDB) {
+		return floorDB
+	}
+	return db
+}
+
+// RealSpectrumFloorDB is the floor that RealSpectrumDB clamps its results to,
+// the level of the rounding error in a float64 FFT of unit-scale data.
+const RealSpectrumFloorDB = -300
+
+// RealSpectrumDB computes the one-sided magnitude spectrum of real x in decibels,
+// for quick plotting. x is multiplied by window, zero-padded to the next power of 2, N,
+// and transformed, and the N/2+1 bins from 0 to sampleRate/2 are converted to
+// 20*log10(|X[k]|), clamped to RealSpectrumFloorDB.
+// freqs holds the frequency of each bin, k*sampleRate/N.
+// This does not alter x, and returns new arrays.
+// len(x) must be positive, otherwise this will return an error.
+func RealSpectrumDB(x []float64, window Window, sampleRate float64) (freqs, db []float64, err error) {
+	if err := checkPositive("RealSpectrumDB input length", len(x)); err != nil {
+		return nil, nil, err
+	}
+	N := NextPow2(len(x))
+	// Pad the window with zeros too, so that only x itself is windowed
+	xp := make([]float64, N)
+	copy(xp, x)
+	weights := make([]float64, N)
+	copy(weights, windowWeights(window, len(x)))
+	db = make([]float64, N/2+1)
+	rfftMagnitude(xp, weights, rfftTwiddles(N), make([]complex128, N/2), db)
+	freqs = make([]float64, N/2+1)
+	for k, v := range db {
+		db[k] = toDB(20*math.Log10(v), RealSpectrumFloorDB)
+		freqs[k] = float64(k) * sampleRate / float64(N)
+	}
+	return freqs, db, nil
+}
+
+// PSD computes the one-sided power spectral density, in units^2 per Hz, from the
+// FFT x of a frame of len(x) samples taken at sampleRate with window applied.
+// Each bin is scaled by 1/(sampleRate*sum(w^2)) to correct for the window's gain,
+// and bins other than DC and Nyquist are doubled to fold in the negative frequencies,
+// matching the density scaling of scipy.signal.welch and scipy.signal.periodogram.
+// Returns the len(x)/2+1 bins from 0 to sampleRate/2. Returns nil if x is empty.
+// Assumes x is the FFT of a real signal.
+func PSD(x []complex128, window Window, sampleRate float64) []float64 {
+	N := len(x)
+	if N == 0 {
+		return nil
+	}
+	s2 := 0.0
+	for _, w := range windowWeights(window, N) {
+		s2 += w * w
+	}
+	scale := 1 / (sampleRate * s2)
+	y := OneSidedPower(x)
+	for k := range y {
+		y[k] *= scale
+	}
+	return y
+}
+
+// Welch estimates the one-sided power spectral density of real x sampled at sampleRate
+// using Welch's method, averaging the PSD of each segment of frameSize samples,
+// each starting hopSize samples after the previous, with window applied.
+// Only whole segments are used, and segments are not detrended, matching
+// scipy.signal.welch(x, sampleRate, window, frameSize, frameSize-hopSize, detrend=False).
+// Returns the frameSize/2+1 bins from 0 to sampleRate/2.
+// frameSize must be a perfect power of 2 no greater than len(x), and hopSize must be positive,
+// otherwise this will return an error.
+func Welch(x []float64, frameSize, hopSize int, window Window, sampleRate float64) ([]float64, error) {
+	if err := checkLength("Welch frame size", frameSize); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("Welch hop size", hopSize); err != nil {
+		return nil, err
+	}
+	if len(x) < frameSize {
+		return nil, &InputSizeError{Context: "Welch input length", Requirement: fmt.Sprintf("at least %d", frameSize), Size: len(x), Err: ErrOutOfRange}
+	}
+	numFrames := 1 + (len(x)-frameSize)/hopSize
+	weights := windowWeights(window, frameSize)
+	frame := make([]complex128, frameSize)
+	y := make([]float64, frameSize/2+1)
+	for i := 0; i < numFrames; i++ {
+		for j, v := range x[i*hopSize : i*hopSize+frameSize] {
+			frame[j] = complex(v*weights[j], 0)
+		}
+		fft(frame)
+		for k, v := range PSD(frame, window, sampleRate) {
+			y[k] += v
+		}
+	}
+	for k := range y {
+		y[k] /= float64(numFrames)
+	}
+	return y, nil
+}
+
+// ParabolicPeak refines the location and height of a peak in mag at peakBin by
+// fitting a parabola through it and its two neighbours, returning the offset of
+// the true peak from peakBin, in the range [-0.5, 0.5], and its interpolated value.
+// If peakBin is the first or last bin, or the three points are collinear,
+// no interpolation is possible and this returns 0 and mag[peakBin].
+func ParabolicPeak(mag []float64, peakBin int) (binOffset, peakValue float64) {
+	b := mag[peakBin]
+	if peakBin == 0 || peakBin == len(mag)-1 {
+		return 0, b
+	}
+	a, c := mag[peakBin-1], mag[peakBin+1]
+	d := a - 2*b + c
+	if d == 0 {
+		return 0, b
+	}
+	binOffset = 0.5 * (a - c) / d
+	return binOffset, b - 0.25*(a-c)*binOffset
+}
+
+// EstimatePeakFreq estimates the frequency of the largest magnitude bin of x,
+// the FFT of a signal sampled at sampleRate, refined between bins with ParabolicPeak.
+// Bins are mapped to frequencies as by FFTFreq, so the upper half of x gives negative frequencies.
+// Returns 0 if x is empty.
+func EstimatePeakFreq(x []complex128, sampleRate float64) float64 {
+	N := len(x)
+	if N == 0 {
+		return 0
+	}
+	mag := Magnitude(x)
+	k := 0
+	for i, v := range mag {
+		if v > mag[k] {
+			k = i
+		}
+	}
+	offset, _ := ParabolicPeak(mag, k)
+	f := (float64(k) + offset) * sampleRate / float64(N)
+	if N > 1 && 2*k >= N {
+		f -= sampleRate
+	}
+	return f
+}
+
+// DominantFrequency finds the strongest frequency in real x sampled at sampleRate,
+// for quick tone detection. x is zero-padded to the next power of 2, N, and transformed,
+// and the largest of the one-sided bins 1 to N/2 is refined with ParabolicPeak.
+// DC is ignored, and the mirrored negative frequencies are never considered, so
+// a peak at bin 1 or N/2 has only one neighbour, and is not refined.
+// magnitude is the interpolated |X[k]| of the unnormalized FFT, which is about
+// amplitude*len(x)/2 for a sinusoid with no window.
+// This does not alter x.
+// len(x) must be at least 2, otherwise this will return an error.
+func DominantFrequency(x []float64, sampleRate float64) (freq, magnitude float64, err error) {
+	if len(x) < 2 {
+		return 0, 0, &InputSizeError{Context: "DominantFrequency input length", Requirement: "at least 2", Size: len(x), Err: ErrOutOfRange}
+	}
+	N := NextPow2(len(x))
+	xp := make([]float64, N)
+	copy(xp, x)
+	mag := make([]float64, N/2+1)
+	rfftMagnitude(xp, windowWeights(Rectangular, N), rfftTwiddles(N), make([]complex128, N/2), mag)
+	k := 1
+	for i := 2; i < len(mag); i++ {
+		if mag[i] > mag[k] {
+			k = i
+		}
+	}
+	// Leave DC out of the refinement, as it may hold an unrelated offset
+	offset, magnitude := ParabolicPeak(mag[1:], k-1)
+	return (float64(k) + offset) * sampleRate / float64(N), magnitude, nil
+}
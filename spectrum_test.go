@@ -0,0 +1,341 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestMagnitudePhase(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		x := complexRand(i)
+		m := Magnitude(x)
+		p := Phase(x)
+		if len(m) != i || len(p) != i {
+			t.Errorf("Magnitude and Phase lengths, got: %d and %d, expected: %d", len(m), len(p), i)
+			continue
+		}
+		// Test cmplx.Rect(Magnitude(x), Phase(x)) == x
+		for j := range x {
+			if e := cmplx.Abs(cmplx.Rect(m[j], p[j]) - x[j]); e > 1e-12 {
+				t.Errorf("Magnitude and Phase don't reconstruct x[%d]=%v, got: %v", j, x[j], cmplx.Rect(m[j], p[j]))
+			}
+		}
+	}
+}
+
+func TestEnergy(t *testing.T) {
+	if got := Energy([]complex128{3 + 4i, 1, 2i}); got != 30 {
+		t.Errorf("Energy([3+4i, 1, 2i]), got: %v, expected: 30", got)
+	}
+	// Test Parseval's theorem holds for FFT and IFFT, guarding their normalization
+	for N := 1; N < (1 << 11); N <<= 1 {
+		x := complexRand(N)
+		y := copyVector(x)
+		FFT(y)
+		if e := math.Abs(Energy(x) - Energy(y)/float64(N)); e > 1e-9*Energy(x) {
+			t.Errorf("Parseval differs for FFT: N=%d Energy(x)=%v Energy(FFT(x))/N=%v diff=%v", N, Energy(x), Energy(y)/float64(N), e)
+		}
+		y = copyVector(x)
+		IFFT(y)
+		if e := math.Abs(Energy(x) - Energy(y)*float64(N)); e > 1e-9*Energy(x) {
+			t.Errorf("Parseval differs for IFFT: N=%d Energy(x)=%v Energy(IFFT(x))*N=%v diff=%v", N, Energy(x), Energy(y)*float64(N), e)
+		}
+	}
+}
+
+func TestUnwrapPhase(t *testing.T) {
+	// Test against numpy.unwrap([0, 3, -3, 0.5]), and with discont=4
+	for _, c := range []struct {
+		discont float64
+		expect  []float64
+	}{
+		{math.Pi, []float64{0, 3, 3.2831853071795862, 0.5}},
+		{4, []float64{0, 3, 3.2831853071795862, 6.783185307179586}},
+		{1, []float64{0, 3, 3.2831853071795862, 0.5}},
+	} {
+		got := UnwrapPhaseThreshold([]float64{0, 3, -3, 0.5}, c.discont)
+		for i := range c.expect {
+			if e := math.Abs(got[i] - c.expect[i]); e > 1e-12 {
+				t.Errorf("UnwrapPhaseThreshold(discont=%v), got: %v, expected: %v", c.discont, got, c.expect)
+				break
+			}
+		}
+	}
+	if got := UnwrapPhase(nil); len(got) != 0 {
+		t.Errorf("UnwrapPhase(nil), got: %v, expected: []", got)
+	}
+	// Test unwrapping the Phase of a linear phase ramp recovers the ramp
+	for _, slope := range []float64{0.3, -1.7, 3} {
+		x := make([]complex128, 200)
+		for i := range x {
+			x[i] = cmplx.Rect(1, slope*float64(i))
+		}
+		got := UnwrapPhase(Phase(x))
+		for i := range got {
+			if e := math.Abs(got[i] - slope*float64(i)); e > 1e-9 {
+				t.Errorf("UnwrapPhase of a ramp differs: slope=%v i=%d got=%v expected=%v diff=%v", slope, i, got[i], slope*float64(i), e)
+				break
+			}
+		}
+	}
+}
+
+func TestGroupDelay(t *testing.T) {
+	// Test the group delay of a pure delay of d samples is d/sampleRate everywhere
+	N, fs := 64, 1000.0
+	for _, d := range []int{0, 1, 5, 31} {
+		h := make([]complex128, N)
+		h[d] = 1
+		FFT(h)
+		for k, got := range GroupDelay(h, fs) {
+			if e := math.Abs(got - float64(d)/fs); e > 1e-12 {
+				t.Errorf("GroupDelay of a %d sample delay differs: k=%d got=%v expected=%v diff=%v", d, k, got, float64(d)/fs, e)
+			}
+		}
+	}
+	// Test the group delay of a symmetric FIR of length 2m+1 is m samples, over the first
+	// quarter of the bins, below its zeros at a third of the sample rate.
+	// The sample rate is scaled so that the bin spacing stays fs/N.
+	h := ZeroPad([]complex128{1, 2, 3, 2, 1}, N)
+	FFT(h)
+	delay := GroupDelay(h[:N/4], fs*float64(N/4)/float64(N))
+	for k, got := range delay {
+		if e := math.Abs(got - 2/fs); e > 1e-9 {
+			t.Errorf("GroupDelay of a symmetric FIR differs: k=%d got=%v expected=%v diff=%v", k, got, 2/fs, e)
+		}
+	}
+	if got := GroupDelay([]complex128{1}, fs); len(got) != 1 || got[0] != 0 {
+		t.Errorf("GroupDelay([1]), got: %v, expected: [0]", got)
+	}
+}
+
+func TestMagnitudeDB(t *testing.T) {
+	x := []complex128{0, 1, 10i, complex(-3, 4), 1e-20}
+	expect := []float64{-120, 0, 20, 20 * math.Log10(5), -120}
+	got := MagnitudeDB(x, 1, -120)
+	for i := range expect {
+		if math.Abs(got[i]-expect[i]) > 1e-9 {
+			t.Errorf("MagnitudeDB(%v, 1, -120), got: db[%d] = %v, expected: db[%d] = %v", x[i], i, got[i], i, expect[i])
+		}
+	}
+	// Test the reference level shifts the result
+	got = MagnitudeDB([]complex128{10}, 10, -120)
+	if math.Abs(got[0]) > 1e-9 {
+		t.Errorf("MagnitudeDB([10], 10, -120), got: %v, expected: 0", got[0])
+	}
+}
+
+func TestRealSpectrumDB(t *testing.T) {
+	_, _, err := RealSpectrumDB(nil, Hanning, 1000)
+	checkIsInputSizeError(t, "RealSpectrumDB(nil, Hanning, 1000)", err)
+	sampleRate := 1000.0
+	for _, n := range []int{1, 2, 5, 100, 128} {
+		x := floatRand(n)
+		// Test RealSpectrumDB matches windowing, padding, FFT and MagnitudeDB step by step
+		y := ZeroPadToNextPow2(ApplyWindow(Float64ToComplex128Array(x), Hamming))
+		FFT(y)
+		N := len(y)
+		expect := MagnitudeDB(y[:N/2+1], 1, RealSpectrumFloorDB)
+		freqs, db, err := RealSpectrumDB(x, Hamming, sampleRate)
+		if err != nil {
+			t.Errorf("RealSpectrumDB error: %v", err)
+		}
+		if len(freqs) != N/2+1 || len(db) != N/2+1 {
+			t.Errorf("RealSpectrumDB lengths for n=%d, got: %d and %d, expected: %d", n, len(freqs), len(db), N/2+1)
+			continue
+		}
+		for k := range db {
+			if e := math.Abs(db[k] - expect[k]); e > 1e-9 {
+				t.Errorf("RealSpectrumDB differs: n=%d k=%d got=%v expected=%v diff=%v", n, k, db[k], expect[k], e)
+			}
+			if f := float64(k) * sampleRate / float64(N); freqs[k] != f {
+				t.Errorf("RealSpectrumDB freqs differs: n=%d k=%d got=%v expected=%v", n, k, freqs[k], f)
+			}
+		}
+	}
+	// Test a tone peaks at its own frequency, and silence is clamped to the floor
+	freqs, db, _ := RealSpectrumDB(tone(256, 125, sampleRate, 1), Hanning, sampleRate)
+	k := 0
+	for i := range db {
+		if db[i] > db[k] {
+			k = i
+		}
+	}
+	if freqs[k] != 125 {
+		t.Errorf("RealSpectrumDB peak of a 125Hz tone, got: %vHz", freqs[k])
+	}
+	_, db, _ = RealSpectrumDB(make([]float64, 16), Hanning, sampleRate)
+	for k, v := range db {
+		if v != RealSpectrumFloorDB {
+			t.Errorf("RealSpectrumDB of silence, got: db[%d] = %v, expected: %v", k, v, RealSpectrumFloorDB)
+		}
+	}
+}
+
+func TestPSD(t *testing.T) {
+	if PSD(nil, Hanning, 1) != nil {
+		t.Errorf("PSD(nil), expected nil")
+	}
+	sampleRate := 1000.0
+	for N := 2; N < (1 << 11); N <<= 1 {
+		// Test the integrated PSD of a rectangular-windowed signal is its mean square power (Parseval)
+		x := floatRand(N)
+		y := Float64ToComplex128Array(x)
+		FFT(y)
+		p := PSD(y, Rectangular, sampleRate)
+		if len(p) != N/2+1 {
+			t.Errorf("PSD length, got: %d, expected: %d", len(p), N/2+1)
+		}
+		power := 0.0
+		for _, v := range x {
+			power += v * v / float64(N)
+		}
+		if e := math.Abs(sum(p)*sampleRate/float64(N) - power); e > 1e-9 {
+			t.Errorf("Integrated PSD differs from power: N=%d, got: %v, expected: %v", N, sum(p)*sampleRate/float64(N), power)
+		}
+	}
+	// Test the integrated PSD of a Hanning-windowed tone is its power A^2/2
+	N, A := 1024, 3.0
+	x := make([]complex128, N)
+	for i := range x {
+		x[i] = complex(A*math.Cos(2*math.Pi*100*float64(i)/float64(N)), 0)
+	}
+	ApplyWindow(x, Hanning|Periodic)
+	FFT(x)
+	p := PSD(x, Hanning|Periodic, sampleRate)
+	if got := sum(p) * sampleRate / float64(N); math.Abs(got-A*A/2) > 1e-9 {
+		t.Errorf("Integrated PSD of Hanning-windowed tone, got: %v, expected: %v", got, A*A/2)
+	}
+}
+
+func TestWelch(t *testing.T) {
+	// Test Welch with a non-power of 2 frame size, non-positive hop size, or short input returns InputSizeError
+	_, err := Welch(floatRand(100), 17, 4, Hanning, 1)
+	checkIsInputSizeError(t, "Welch(floatRand(100), 17, 4, Hanning, 1)", err)
+	_, err = Welch(floatRand(100), 16, 0, Hanning, 1)
+	checkIsInputSizeError(t, "Welch(floatRand(100), 16, 0, Hanning, 1)", err)
+	_, err = Welch(floatRand(15), 16, 4, Hanning, 1)
+	checkIsInputSizeError(t, "Welch(floatRand(15), 16, 4, Hanning, 1)", err)
+	// Test Welch is the average of the PSD of each whole segment
+	sampleRate := 100.0
+	x := floatRand(1000)
+	frameSize, hopSize := 64, 32
+	got, err := Welch(x, frameSize, hopSize, Hanning|Periodic, sampleRate)
+	if err != nil {
+		t.Fatalf("Welch error: %v", err)
+	}
+	expect := make([]float64, frameSize/2+1)
+	numFrames := 0
+	for s := 0; s+frameSize <= len(x); s += hopSize {
+		frame := ApplyWindow(Float64ToComplex128Array(x[s:s+frameSize]), Hanning|Periodic)
+		FFT(frame)
+		for k, v := range PSD(frame, Hanning|Periodic, sampleRate) {
+			expect[k] += v
+		}
+		numFrames++
+	}
+	for k := range expect {
+		if e := math.Abs(expect[k]/float64(numFrames) - got[k]); e > 1e-12 {
+			t.Errorf("Welch differs from averaged PSD: k=%d got=%v expected=%v", k, got[k], expect[k]/float64(numFrames))
+		}
+	}
+	// Test the Welch estimate of unit variance white noise is flat at 2/sampleRate
+	x = floatRand(1 << 16)
+	got, _ = Welch(x, 64, 32, Hanning|Periodic, sampleRate)
+	for k := 1; k < len(got)-1; k++ {
+		if math.Abs(got[k]*sampleRate/2-1) > 0.15 {
+			t.Errorf("Welch of white noise isn't flat: k=%d got=%v expected=%v", k, got[k], 2/sampleRate)
+		}
+	}
+}
+
+func TestParabolicPeak(t *testing.T) {
+	// Test a sampled parabola peaking between bins is recovered exactly
+	mag := make([]float64, 10)
+	for i := range mag {
+		d := float64(i) - 4.3
+		mag[i] = 7 - 2*d*d
+	}
+	offset, peak := ParabolicPeak(mag, 4)
+	if math.Abs(offset-0.3) > 1e-12 || math.Abs(peak-7) > 1e-12 {
+		t.Errorf("ParabolicPeak of parabola, got: %v, %v, expected: 0.3, 7", offset, peak)
+	}
+	// Test edge bins and flat neighbourhoods aren't interpolated
+	for _, test := range []struct {
+		mag []float64
+		bin int
+	}{
+		{[]float64{3, 2, 1}, 0},
+		{[]float64{1, 2, 3}, 2},
+		{[]float64{5}, 0},
+		{[]float64{1, 2, 3, 4}, 1},
+	} {
+		offset, peak := ParabolicPeak(test.mag, test.bin)
+		if offset != 0 || peak != test.mag[test.bin] {
+			t.Errorf("ParabolicPeak(%v, %d), got: %v, %v, expected: 0, %v", test.mag, test.bin, offset, peak, test.mag[test.bin])
+		}
+	}
+}
+
+func TestEstimatePeakFreq(t *testing.T) {
+	if f := EstimatePeakFreq(nil, 1000); f != 0 {
+		t.Errorf("EstimatePeakFreq(nil), got: %v, expected: 0", f)
+	}
+	// Test a Hanning-windowed tone between bins is located to within a small fraction of a bin
+	sampleRate, N := 1000.0, 1024
+	for _, freq := range []float64{-300.4, 50.2, 123.45, 321.7} {
+		x := make([]complex128, N)
+		for i := range x {
+			x[i] = cmplx.Rect(1, 2*math.Pi*freq*float64(i)/sampleRate)
+		}
+		ApplyWindow(x, Hanning|Periodic)
+		FFT(x)
+		got := EstimatePeakFreq(x, sampleRate)
+		if math.Abs(got-freq) > 0.05*sampleRate/float64(N) {
+			t.Errorf("EstimatePeakFreq of %v Hz tone, got: %v", freq, got)
+		}
+	}
+}
+
+func TestDominantFrequency(t *testing.T) {
+	_, _, err := DominantFrequency([]float64{1}, 1000)
+	checkIsInputSizeError(t, "DominantFrequency([]float64{1}, 1000)", err)
+	sampleRate := 1000.0
+	// Test tones on and between bins, with a DC offset that must be ignored
+	for _, f := range []float64{125, 130.2, 333.3, 15.6} {
+		x := tone(1024, f, sampleRate, 1)
+		for i := range x {
+			x[i] += 5
+		}
+		freq, magnitude, err := DominantFrequency(x, sampleRate)
+		if err != nil {
+			t.Errorf("DominantFrequency error: %v", err)
+		}
+		// Parabolic interpolation without a window is accurate to within a fraction of a bin
+		if e := math.Abs(freq - f); e > 0.3*sampleRate/1024 {
+			t.Errorf("DominantFrequency of a %vHz tone, got: %vHz, diff=%v", f, freq, e)
+		}
+		if magnitude < 0.6*512 || magnitude > 1.1*512 {
+			t.Errorf("DominantFrequency magnitude of a %vHz tone, got: %v, expected about 512", f, magnitude)
+		}
+	}
+	// Test a peak at bin 1 isn't refined towards a large DC offset
+	x := tone(1024, sampleRate/1024, sampleRate, 1)
+	for i := range x {
+		x[i] += 5
+	}
+	freq, magnitude, _ := DominantFrequency(x, sampleRate)
+	if math.Abs(freq-sampleRate/1024) > 1e-9 || math.Abs(magnitude-512) > 1e-6 {
+		t.Errorf("DominantFrequency of a bin 1 tone with a DC offset, got: %vHz %v, expected: %vHz 512", freq, magnitude, sampleRate/1024)
+	}
+	// Test an on-bin tone has an exact magnitude, and a tone of non-power of 2 length is padded
+	freq, magnitude, _ = DominantFrequency(tone(1024, 250, sampleRate, 2), sampleRate)
+	if freq != 250 || math.Abs(magnitude-1024) > 1e-6 {
+		t.Errorf("DominantFrequency of an on-bin tone, got: %vHz %v, expected: 250Hz 1024", freq, magnitude)
+	}
+	freq, _, _ = DominantFrequency(tone(1000, 250, sampleRate, 1), sampleRate)
+	if e := math.Abs(freq - 250); e > 0.3*sampleRate/1024 {
+		t.Errorf("DominantFrequency of a padded tone, got: %vHz, diff=%v", freq, e)
+	}
+}
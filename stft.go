@@ -0,0 +1,255 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// STFT computes the short-time Fourier transform of x, returning a spectrogram.
+// x is sliced into frames of frameSize samples, each starting hopSize samples
+// after the previous, with the final frame 0-padded to cover the end of x.
+// If hopSize > frameSize, the samples between frames are skipped, and there is
+// a frame for each start before the end of x.
+// Each frame has the window applied, and is then transformed with FFT.
+// Periodic windows, such as Hanning|Periodic, are usually preferred here.
+// This does not alter x. Returns nil if x is empty.
+// frameSize must be a perfect power of 2 and hopSize must be positive,
+// otherwise this will return an error.
+func STFT(x []float64, frameSize, hopSize int, window Window) ([][]complex128, error) {
+	if err := checkLength("STFT frame size", frameSize); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("STFT hop size", hopSize); err != nil {
+		return nil, err
+	}
+	if len(x) == 0 {
+		return nil, nil
+	}
+	numFrames := 1
+	if len(x) > frameSize {
+		numFrames += (len(x) - frameSize + hopSize - 1) / hopSize
+	}
+	// If hopSize > frameSize, frames past the end of x would otherwise be counted
+	numFrames = min(numFrames, (len(x)+hopSize-1)/hopSize)
+	frames := make([][]complex128, numFrames)
+	for i := range frames {
+		frame := make([]complex128, frameSize)
+		for j, v := range x[i*hopSize : min(i*hopSize+frameSize, len(x))] {
+			frame[j] = complex(v, 0)
+		}
+		ApplyWindow(frame, window)
+		fft(frame)
+		frames[i] = frame
+	}
+	return frames, nil
+}
+
+// istftMinWindowSum is the smallest sum of squared windows that ISTFT divides by,
+// matching scipy.signal.istft, below which samples can't be recovered and are set to 0
+const istftMinWindowSum = 1e-10
+
+// ISTFT computes the inverse short-time Fourier transform of frames, such as from STFT
+// with the same frameSize, hopSize and window, returning the real signal.
+// Each frame is transformed with IFFT, multiplied by window again as a synthesis window,
+// and overlap-added hopSize samples after the previous. Each sample is then divided
+// by the sum of the squared windows overlapping it, which undoes the gain of both
+// windows for any window, at the edges as well as the middle.
+// Samples where that sum is below 1e-10, such as where a window is 0 at the very
+// start or end, can't be recovered and are set to 0. With a window and hopSize that
+// satisfy CheckCOLA, every sample away from the edges is recovered.
+// Returns (len(frames)-1)*hopSize+frameSize samples, which includes any 0-padding
+// STFT added to the final frame. This does not alter frames. Returns nil if frames is empty.
+// frameSize must be a perfect power of 2, hopSize must be positive, and every frame
+// must have frameSize bins, otherwise this will return an error.
+func ISTFT(frames [][]complex128, frameSize, hopSize int, window Window) ([]float64, error) {
+	if err := checkLength("ISTFT frame size", frameSize); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("ISTFT hop size", hopSize); err != nil {
+		return nil, err
+	}
+	for i, frame := range frames {
+		if err := checkZero(fmt.Sprintf("difference in ISTFT frame %d length and frame size", i), len(frame)-frameSize); err != nil {
+			return nil, err
+		}
+	}
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	weights := windowWeights(window, frameSize)
+	n := (len(frames)-1)*hopSize + frameSize
+	y := make([]float64, n)
+	windowSum := make([]float64, n)
+	z := make([]complex128, frameSize)
+	for i, frame := range frames {
+		copy(z, frame)
+		ifft(z)
+		for j, v := range z {
+			w := weights[j]
+			y[i*hopSize+j] += real(v) * w
+			windowSum[i*hopSize+j] += w * w
+		}
+	}
+	for t, s := range windowSum {
+		if s > istftMinWindowSum {
+			y[t] /= s
+		} else {
+			y[t] = 0
+		}
+	}
+	return y, nil
+}
+
+// SpectrogramImage converts frames, a spectrogram such as from STFT, into a matrix
+// of intensities in [0, 1] that can be rendered directly as a heatmap.
+// Each bin is converted to 20*log10(|X|), clamped to the dynamicRangeDB decibels
+// below the loudest bin across all frames, and scaled so that the loudest bin is 1
+// and anything at or below the clamp is 0.
+// Bins of 0 magnitude give 0, and if every bin is 0 the whole matrix is 0.
+// All of the rows share one backing array. This does not alter frames.
+// Returns nil if frames is empty or dynamicRangeDB is not positive.
+func SpectrogramImage(frames [][]complex128, dynamicRangeDB float64) [][]float64 {
+	if len(frames) == 0 || !(dynamicRangeDB > 0) {
+		return nil
+	}
+	size := 0
+	for _, frame := range frames {
+		size += len(frame)
+	}
+	data := make([]float64, size)
+	img := make([][]float64, len(frames))
+	maxDB := math.Inf(-1)
+	for i, frame := range frames {
+		img[i], data = data[:len(frame):len(frame)], data[len(frame):]
+		for k, v := range frame {
+			img[i][k] = 20 * math.Log10(cmplx.Abs(v))
+			maxDB = math.Max(maxDB, img[i][k])
+		}
+	}
+	if math.IsInf(maxDB, -1) {
+		// Every bin is 0, so there is no loudest bin to scale to
+		for _, row := range img {
+			clear(row)
+		}
+		return img
+	}
+	floorDB := maxDB - dynamicRangeDB
+	for _, row := range img {
+		for k, db := range row {
+			row[k] = (toDB(db, floorDB) - floorDB) / dynamicRangeDB
+		}
+	}
+	return img
+}
+
+// colaTolerance is the largest deviation of the overlap-added windows from their
+// mean that CheckCOLA treats as constant, matching scipy.signal.check_COLA
+const colaTolerance = 1e-10
+
+// CheckCOLA reports whether window satisfies the constant overlap-add (COLA)
+// condition for frames of frameSize samples every hopSize samples: whether the
+// sum of the windows of every frame overlapping a sample is the same for every sample,
+// away from the ends of the signal. This is what lets overlapping windowed frames
+// add back up to the original signal without amplitude ripple, as in ISTFT.
+// The second result is that constant sum, which the overlap-add must be divided by,
+// or the mean sum if it isn't constant.
+// This matches scipy.signal.check_COLA. Most windows need the Periodic form to be COLA:
+//   - Rectangular at any hopSize dividing frameSize, with a sum of frameSize/hopSize
+//   - Hanning|Periodic and Hamming|Periodic at any hopSize dividing frameSize,
+//     including 50% and 75% overlap
+//   - Bartlett, in either form, at 50% and 75% overlap
+//   - Blackman|Periodic at 2/3 and 75% overlap
+//   - BlackmanHarris|Periodic, and Tukey|Periodic with its default fraction, at 75% overlap
+//
+// Kaiser, Gaussian and FlatTop are not COLA at 50% or 75% overlap.
+//
+// Returns false, 0 unless 0 < hopSize <= frameSize.
+func CheckCOLA(window Window, frameSize, hopSize int) (bool, float64) {
+	if hopSize <= 0 || hopSize > frameSize {
+		return false, 0
+	}
+	weights := windowWeights(window, frameSize)
+	// sums[n] is the overlap-add at every sample n mod hopSize
+	sums := make([]float64, hopSize)
+	for i, w := range weights {
+		sums[i%hopSize] += w
+	}
+	mean := 0.0
+	for _, v := range sums {
+		mean += v
+	}
+	mean /= float64(hopSize)
+	for _, v := range sums {
+		if math.Abs(v-mean) > colaTolerance {
+			return false, mean
+		}
+	}
+	return true, mean
+}
+
+// Streamer computes the short-time Fourier transform of a continuous signal,
+// emitting a new windowed spectrum every hopSize samples once the first
+// frameSize samples have arrived. Samples are accumulated in a ring buffer,
+// and spectra are computed on reused buffers.
+//
+// A Streamer is not safe for concurrent use.
+type Streamer struct {
+	weights   []float64 // window function values
+	hopSize   int
+	ring      []float64 // the last frameSize samples
+	pos       int       // next write position in ring
+	untilNext int       // samples remaining until the next spectrum
+	spectra   [][]complex128
+}
+
+// NewStreamer creates a Streamer emitting spectra of frames of frameSize samples,
+// each starting hopSize samples after the previous, with the window applied.
+// frameSize must be a perfect power of 2 and hopSize must be positive,
+// otherwise this will return an error.
+func NewStreamer(frameSize, hopSize int, window Window) (*Streamer, error) {
+	if err := checkLength("Streamer frame size", frameSize); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("Streamer hop size", hopSize); err != nil {
+		return nil, err
+	}
+	return &Streamer{
+		weights:   windowWeights(window, frameSize),
+		hopSize:   hopSize,
+		ring:      make([]float64, frameSize),
+		untilNext: frameSize,
+	}, nil
+}
+
+// Push adds samples to the stream, returning the spectra of any frames
+// completed by them, in order, or nil if there are none.
+// The returned spectra are reused by the next call to Push, so must be
+// copied if they are needed afterwards.
+func (s *Streamer) Push(samples []float64) [][]complex128 {
+	n := 0
+	N := len(s.ring)
+	for _, v := range samples {
+		s.ring[s.pos] = v
+		s.pos = (s.pos + 1) % N
+		s.untilNext--
+		if s.untilNext > 0 {
+			continue
+		}
+		s.untilNext = s.hopSize
+		if n == len(s.spectra) {
+			s.spectra = append(s.spectra, make([]complex128, N))
+		}
+		frame := s.spectra[n]
+		// The oldest sample is at the write position
+		for j := range frame {
+			frame[j] = complex(s.ring[(s.pos+j)%N]*s.weights[j], 0)
+		}
+		fft(frame)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	return s.spectra[:n]
+}
@@ -0,0 +1,244 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestSTFT(t *testing.T) {
+	// Test STFT of a non-power of 2 frame size or non-positive hop size returns InputSizeError
+	_, err := STFT(floatRand(100), 17, 4, Hanning)
+	checkIsInputSizeError(t, "STFT(floatRand(100), 17, 4, Hanning)", err)
+	_, err = STFT(floatRand(100), 16, 0, Hanning)
+	checkIsInputSizeError(t, "STFT(floatRand(100), 16, 0, Hanning)", err)
+	// Test STFT of an empty input returns nil
+	frames, err := STFT(nil, 16, 4, Hanning)
+	if err != nil || frames != nil {
+		t.Errorf("STFT(nil, 16, 4, Hanning), got: %v, %v, expected: nil, nil", frames, err)
+	}
+	// Test the frame count, and that each frame == slowFFT(ApplyWindow(frame))
+	for _, test := range []struct {
+		n, frameSize, hopSize, numFrames int
+	}{
+		{1, 16, 4, 1},
+		{16, 16, 4, 1},
+		{17, 16, 4, 2},
+		{20, 16, 4, 2},
+		{100, 32, 8, 10},
+		{100, 32, 32, 4},
+		{17, 16, 100, 1},
+		{250, 16, 100, 3},
+		{300, 16, 100, 3},
+		{301, 16, 100, 4},
+	} {
+		x := floatRand(test.n)
+		frames, err := STFT(x, test.frameSize, test.hopSize, Hamming)
+		if err != nil {
+			t.Errorf("STFT error: %v", err)
+		}
+		if len(frames) != test.numFrames {
+			t.Errorf("STFT(floatRand(%d), %d, %d) frame count, got: %d, expected: %d", test.n, test.frameSize, test.hopSize, len(frames), test.numFrames)
+		}
+		for i, frame := range frames {
+			s := i * test.hopSize
+			expect := ZeroPad(Float64ToComplex128Array(x[s:min(s+test.frameSize, len(x))]), test.frameSize)
+			expect = slowFFT(ApplyWindow(expect, Hamming))
+			for k := range frame {
+				if e := cmplx.Abs(expect[k] - frame[k]); e > 1e-9 {
+					t.Errorf("STFT frame %d differs: k=%d got=%v expected=%v diff=%v", i, k, frame[k], expect[k], e)
+				}
+			}
+		}
+	}
+}
+
+func TestSpectrogramImage(t *testing.T) {
+	if SpectrogramImage(nil, 60) != nil || SpectrogramImage([][]complex128{{1}}, 0) != nil {
+		t.Errorf("SpectrogramImage of no frames or a non-positive range, expected nil")
+	}
+	frames := [][]complex128{
+		{100, 10i, complex(0.6, 0.8)},
+		{0, 1e-9, -1000},
+	}
+	// -1000 is the loudest at 60dB, so 100 (40dB) is at 2/3 of a 60dB range,
+	// 10i (20dB) at 1/3, 1 (0dB) at 0, and 0 and 1e-9 are clamped to 0
+	expect := [][]float64{{2.0 / 3, 1.0 / 3, 0}, {0, 0, 1}}
+	img := SpectrogramImage(frames, 60)
+	for i := range expect {
+		for k := range expect[i] {
+			if e := math.Abs(img[i][k] - expect[i][k]); e > 1e-9 {
+				t.Errorf("SpectrogramImage differs: frame=%d k=%d got=%v expected=%v diff=%v", i, k, img[i][k], expect[i][k], e)
+			}
+		}
+	}
+	// Test frames of all zeros give all zeros, without NaNs
+	img = SpectrogramImage([][]complex128{make([]complex128, 4), make([]complex128, 4)}, 80)
+	for i, row := range img {
+		for k, v := range row {
+			if v != 0 {
+				t.Errorf("SpectrogramImage of silence, got: img[%d][%d] = %v, expected: 0", i, k, v)
+			}
+		}
+	}
+	// Test STFT output gives values in [0, 1]
+	frames, _ = STFT(floatRand(256), 32, 16, Hanning|Periodic)
+	for i, row := range SpectrogramImage(frames, 80) {
+		for k, v := range row {
+			if !(v >= 0 && v <= 1) {
+				t.Errorf("SpectrogramImage of STFT out of range: img[%d][%d] = %v", i, k, v)
+			}
+		}
+	}
+}
+
+func TestCheckCOLA(t *testing.T) {
+	for _, test := range []struct {
+		window             Window
+		frameSize, hopSize int
+		cola               bool
+		sum                float64
+	}{
+		{Rectangular, 64, 16, true, 4},
+		{Hanning | Periodic, 64, 32, true, 1},
+		{Hanning | Periodic, 64, 16, true, 2},
+		{Hanning, 64, 32, false, 0},
+		{Hamming | Periodic, 64, 32, true, 1.08},
+		{Bartlett | Periodic, 64, 32, true, 1},
+		{Blackman | Periodic, 96, 32, true, 1.26},
+		{Blackman | Periodic, 64, 32, false, 0},
+		{BlackmanHarris | Periodic, 64, 16, true, 1.435},
+		{Tukey | Periodic, 64, 16, true, 3},
+		{Kaiser | Periodic, 64, 16, false, 0},
+		{FlatTop | Periodic, 64, 16, false, 0},
+	} {
+		cola, sum := CheckCOLA(test.window, test.frameSize, test.hopSize)
+		if cola != test.cola || (cola && math.Abs(sum-test.sum) > 1e-9) {
+			t.Errorf("CheckCOLA(%d, %d, %d), got: %t %v, expected: %t %v", test.window, test.frameSize, test.hopSize, cola, sum, test.cola, test.sum)
+		}
+	}
+	// Test invalid hop sizes
+	for _, hopSize := range []int{0, -1, 65} {
+		if cola, sum := CheckCOLA(Hanning|Periodic, 64, hopSize); cola || sum != 0 {
+			t.Errorf("CheckCOLA(Hanning|Periodic, 64, %d), got: %t %v, expected: false 0", hopSize, cola, sum)
+		}
+	}
+	// Test overlap-adding COLA windows directly is constant at the returned sum
+	window, frameSize, hopSize := Hamming|Periodic, 32, 8
+	_, sum := CheckCOLA(window, frameSize, hopSize)
+	weights := windowWeights(window, frameSize)
+	ola := make([]float64, 10*frameSize)
+	for s := 0; s+frameSize <= len(ola); s += hopSize {
+		for i, w := range weights {
+			ola[s+i] += w
+		}
+	}
+	for i := frameSize; i < len(ola)-frameSize; i++ {
+		if e := math.Abs(ola[i] - sum); e > 1e-9 {
+			t.Errorf("overlap-added windows differ from CheckCOLA sum: i=%d got=%v expected=%v diff=%v", i, ola[i], sum, e)
+		}
+	}
+}
+
+func TestISTFT(t *testing.T) {
+	// Test invalid sizes return InputSizeError
+	_, err := ISTFT([][]complex128{complexRand(16)}, 12, 4, Hanning)
+	checkIsInputSizeError(t, "ISTFT([][]complex128{complexRand(16)}, 12, 4, Hanning)", err)
+	_, err = ISTFT([][]complex128{complexRand(16)}, 16, 0, Hanning)
+	checkIsInputSizeError(t, "ISTFT([][]complex128{complexRand(16)}, 16, 0, Hanning)", err)
+	_, err = ISTFT([][]complex128{complexRand(16), complexRand(8)}, 16, 4, Hanning)
+	checkIsInputSizeError(t, "ISTFT with a frame of the wrong length", err)
+	if y, err := ISTFT(nil, 16, 4, Hanning); y != nil || err != nil {
+		t.Errorf("ISTFT(nil), got: %v, %v, expected: nil, nil", y, err)
+	}
+	// Test ISTFT(STFT(x)) == x for COLA windows and hops, and non-COLA ones
+	for _, test := range []struct {
+		window             Window
+		frameSize, hopSize int
+		n                  int
+	}{
+		{Hamming | Periodic, 32, 16, 200},
+		{Hamming | Periodic, 32, 8, 100},
+		{Rectangular, 16, 16, 64},
+		{BlackmanHarris | Periodic, 64, 16, 300},
+		{Kaiser, 32, 8, 150},
+		{Hamming, 16, 4, 1},
+	} {
+		x := floatRand(test.n)
+		frames, _ := STFT(x, test.frameSize, test.hopSize, test.window)
+		y, err := ISTFT(frames, test.frameSize, test.hopSize, test.window)
+		if err != nil {
+			t.Errorf("ISTFT error: %v", err)
+		}
+		if expect := (len(frames)-1)*test.hopSize + test.frameSize; len(y) != expect {
+			t.Errorf("ISTFT length, got: %d, expected: %d", len(y), expect)
+			continue
+		}
+		for i := range x {
+			if e := math.Abs(x[i] - y[i]); e > 1e-9 {
+				t.Errorf("ISTFT(STFT(x)) differs: window=%d frameSize=%d hopSize=%d i=%d got=%v expected=%v diff=%v", test.window, test.frameSize, test.hopSize, i, y[i], x[i], e)
+			}
+		}
+	}
+	// Test samples where every window is 0 can't be recovered, and are 0 rather than NaN
+	x := floatRand(128)
+	frames, _ := STFT(x, 32, 16, Hanning|Periodic)
+	y, _ := ISTFT(frames, 32, 16, Hanning|Periodic)
+	if y[0] != 0 {
+		t.Errorf("ISTFT at a sample where the window is 0, got: %v, expected: 0", y[0])
+	}
+	for i := 1; i < len(x); i++ {
+		if e := math.Abs(x[i] - y[i]); e > 1e-9 {
+			t.Errorf("ISTFT(STFT(x)) with Hanning|Periodic differs: i=%d got=%v expected=%v diff=%v", i, y[i], x[i], e)
+		}
+	}
+}
+
+func TestStreamer(t *testing.T) {
+	// Test NewStreamer of a non-power of 2 frame size or non-positive hop size returns InputSizeError
+	_, err := NewStreamer(17, 4, Hanning)
+	checkIsInputSizeError(t, "NewStreamer(17, 4, Hanning)", err)
+	_, err = NewStreamer(16, 0, Hanning)
+	checkIsInputSizeError(t, "NewStreamer(16, 0, Hanning)", err)
+	// Test pushing random chunks gives the same spectra as STFT on the whole signal
+	for _, test := range []struct {
+		frameSize, hopSize int
+	}{
+		{1, 1},
+		{16, 4},
+		{16, 16},
+		{16, 20},
+		{64, 24},
+	} {
+		numFrames := 12
+		x := floatRand(test.frameSize + (numFrames-1)*test.hopSize)
+		expect, err := STFT(x, test.frameSize, test.hopSize, Hanning|Periodic)
+		if err != nil {
+			t.Fatalf("STFT error: %v", err)
+		}
+		s, err := NewStreamer(test.frameSize, test.hopSize, Hanning|Periodic)
+		if err != nil {
+			t.Fatalf("NewStreamer error: %v", err)
+		}
+		var got [][]complex128
+		for i := 0; i < len(x); {
+			e := min(i+rand.Intn(3*test.hopSize+1), len(x))
+			for _, spectrum := range s.Push(x[i:e]) {
+				got = append(got, copyVector(spectrum))
+			}
+			i = e
+		}
+		if len(got) != len(expect) {
+			t.Errorf("Streamer(%d, %d) spectra count, got: %d, expected: %d", test.frameSize, test.hopSize, len(got), len(expect))
+			continue
+		}
+		for i := range got {
+			for k := range got[i] {
+				if e := cmplx.Abs(expect[i][k] - got[i][k]); e > 1e-9 {
+					t.Errorf("Streamer(%d, %d) and STFT differ: frame=%d k=%d got=%v expected=%v", test.frameSize, test.hopSize, i, k, got[i][k], expect[i][k])
+				}
+			}
+		}
+	}
+}
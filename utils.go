@@ -1,10 +1,17 @@
 package fft
 
 import (
+	"fmt"
 	"math"
 	"math/bits"
+	"math/cmplx"
 )
 
+// MaxLength is the largest power of 2 that an int can hold, and so the largest
+// supported transform length: 2^62 on 64-bit platforms, and 2^30 on 32-bit ones.
+// All of the index arithmetic is safe up to this length.
+const MaxLength = 1 << (bits.UintSize - 2)
+
 // IsPow2 returns true if N is a perfect power of 2 (1, 2, 4, 8, ...) and false otherwise.
 // Algorithm from: https://graphics.stanford.edu/~seander/bithacks.html#DetermineIfPowerOf2
 func IsPow2(N int) bool {
@@ -15,11 +22,26 @@ func IsPow2(N int) bool {
 }
 
 // NextPow2 returns the smallest power of 2 >= N.
+// N must be at most MaxLength, otherwise the result overflows.
 func NextPow2(N int) int {
+	return 1 << uint64(NextPow2Exp(N))
+}
+
+// NextPow2Exp returns the exponent of NextPow2(N), the smallest k such that 2^k >= N.
+func NextPow2Exp(N int) int {
 	if N == 0 {
-		return 1
+		return 0
+	}
+	return bits.Len64(uint64(N - 1))
+}
+
+// Log2 returns the exponent k such that 2^k == N, and true, if N is a perfect power of 2.
+// Otherwise returns 0 and false.
+func Log2(N int) (int, bool) {
+	if !IsPow2(N) {
+		return 0, false
 	}
-	return 1 << uint64(bits.Len64(uint64(N-1)))
+	return bits.TrailingZeros64(uint64(N)), true
 }
 
 // ZeroPad pads x with 0s at the end into a new array of length N.
@@ -32,6 +54,78 @@ func ZeroPad(x []complex128, N int) []complex128 {
 	return y
 }
 
+// PadMode selects the values that Pad fills the end of an array with.
+type PadMode int
+
+const (
+	// PadZero pads with 0s, as ZeroPad does.
+	PadZero PadMode = iota
+	// PadEdge pads by repeating the last value, like numpy.pad mode="edge".
+	PadEdge
+	// PadReflect pads with the values before the last in reverse, mirrored about the
+	// last value without repeating it, like numpy.pad mode="reflect".
+	// x = [1, 2, 3] pads to [1, 2, 3, 2, 1, 2, 3, ...].
+	PadReflect
+)
+
+// Pad pads x at the end into a new array of length N, filled according to mode,
+// which reduces the boundary artifacts of padding with 0s for some spectral estimates.
+// Pad(x, N, PadZero) is the same as ZeroPad(x, N). If N < len(x), x is truncated.
+// PadEdge and PadReflect have no values to repeat if x is empty, so they pad with 0s,
+// as do unknown modes.
+// This does not alter x, and creates an entirely new array.
+func Pad(x []complex128, N int, mode PadMode) []complex128 {
+	y := ZeroPad(x, N)
+	n := len(x)
+	if n == 0 || n >= N {
+		return y
+	}
+	switch mode {
+	case PadEdge:
+		for i := n; i < N; i++ {
+			y[i] = x[n-1]
+		}
+	case PadReflect:
+		if n == 1 {
+			for i := n; i < N; i++ {
+				y[i] = x[0]
+			}
+			break
+		}
+		// The reflections repeat with period 2(n-1)
+		period := 2 * (n - 1)
+		for i := n; i < N; i++ {
+			j := i % period
+			if j >= n {
+				j = period - j
+			}
+			y[i] = x[j]
+		}
+	}
+	return y
+}
+
+// PadConstant pads x at the end into a new array of length N, filled with value,
+// like numpy.pad mode="constant". If N < len(x), x is truncated.
+// This does not alter x, and creates an entirely new array.
+func PadConstant(x []complex128, N int, value complex128) []complex128 {
+	y := ZeroPad(x, N)
+	for i := len(x); i < N; i++ {
+		y[i] = value
+	}
+	return y
+}
+
+// ZeroPadTo pads x with 0s at the end into a new array of length N, like ZeroPad,
+// but returns an error instead of truncating x if N < len(x).
+// This does not alter x, and creates an entirely new array.
+func ZeroPadTo(x []complex128, N int) ([]complex128, error) {
+	if N < len(x) {
+		return nil, &InputSizeError{Context: "ZeroPadTo length", Requirement: fmt.Sprintf("at least the input length %d", len(x)), Size: N, Err: ErrOutOfRange}
+	}
+	return ZeroPad(x, N), nil
+}
+
 // ZeroPadToNextPow2 pads x with 0s at the end into a new array of length 2^N >= len(x)
 // This does not alter x, and creates an entirely new array.
 // This should only be used as a convience function, and isn't meant for performance.
@@ -63,6 +157,63 @@ func Complex128ToFloat64Array(x []complex128) []float64 {
 	return y
 }
 
+// InterleavedToComplex128 converts an interleaved [re0, im0, re1, im1, ...] float64 array
+// to the equivalent complex128 array of half the length.
+// len(x) must be even, otherwise this will return an error.
+func InterleavedToComplex128(x []float64) ([]complex128, error) {
+	if len(x)%2 != 0 {
+		return nil, &InputSizeError{Context: "InterleavedToComplex128 input length", Requirement: "even", Size: len(x), Err: ErrOddLength}
+	}
+	y := make([]complex128, len(x)/2)
+	for i := range y {
+		y[i] = complex(x[2*i], x[2*i+1])
+	}
+	return y, nil
+}
+
+// Complex128ToInterleaved converts a complex128 array to the equivalent interleaved
+// [re0, im0, re1, im1, ...] float64 array of twice the length.
+func Complex128ToInterleaved(x []complex128) []float64 {
+	y := make([]float64, 2*len(x))
+	for i, v := range x {
+		y[2*i] = real(v)
+		y[2*i+1] = imag(v)
+	}
+	return y
+}
+
+// Deinterleave splits x into its even-indexed and odd-indexed elements,
+// as in a decimation-in-time split, returning them as new arrays.
+// If len(x) is odd, even has one more element than odd. This does not alter x.
+func Deinterleave(x []complex128) (even, odd []complex128) {
+	even = make([]complex128, (len(x)+1)/2)
+	odd = make([]complex128, len(x)/2)
+	for i := range even {
+		even[i] = x[2*i]
+	}
+	for i := range odd {
+		odd[i] = x[2*i+1]
+	}
+	return even, odd
+}
+
+// Interleave merges even and odd into a new array with even in the even indices
+// and odd in the odd indices, inverting Deinterleave.
+// len(even) must equal len(odd) or len(odd)+1, otherwise this will panic.
+func Interleave(even, odd []complex128) []complex128 {
+	if d := len(even) - len(odd); d != 0 && d != 1 {
+		panic("fft: Interleave requires len(even) to equal len(odd) or len(odd)+1")
+	}
+	y := make([]complex128, len(even)+len(odd))
+	for i, v := range even {
+		y[2*i] = v
+	}
+	for i, v := range odd {
+		y[2*i+1] = v
+	}
+	return y
+}
+
 // RoundFloat64Array calls math.Round on each entry in x, changing the array in-place
 func RoundFloat64Array(x []float64) {
 	for i, v := range x {
@@ -70,6 +221,21 @@ func RoundFloat64Array(x []float64) {
 	}
 }
 
+// CleanFloat64Array snaps each entry in x within tol of an integer to that
+// integer, such as to clean floating-point noise from a convolution of integers,
+// leaving the other entries unchanged. Entries within tol of 0 become exactly 0,
+// never -0. This changes the array in-place.
+func CleanFloat64Array(x []float64, tol float64) {
+	for i, v := range x {
+		if r := math.Round(v); math.Abs(v-r) <= tol {
+			if r == 0 {
+				r = 0 // Replace -0
+			}
+			x[i] = r
+		}
+	}
+}
+
 // Complex64ToComplex128 converts a slice of complex64 to complex128
 func Complex64ToComplex128(data []complex64) []complex128 {
 	result := make([]complex128, len(data))
@@ -87,3 +253,91 @@ func Complex128ToComplex64(data []complex128) []complex64 {
 	}
 	return result
 }
+
+// FFTFreq returns the n frequencies corresponding to each bin of an FFT of
+// length n on data sampled at sampleRate, in the same order as the FFT output:
+// 0, the positive frequencies, then the negative frequencies.
+// This matches numpy.fft.fftfreq(n, 1/sampleRate), so for even n the
+// Nyquist bin n/2 is reported as the negative frequency -sampleRate/2.
+// n must be even and positive, otherwise this returns nil.
+func FFTFreq(n int, sampleRate float64) []float64 {
+	if n <= 0 || n%2 != 0 {
+		return nil
+	}
+	f := make([]float64, n)
+	df := sampleRate / float64(n)
+	for i := 0; i < n/2; i++ {
+		f[i] = float64(i) * df
+		f[n-1-i] = -float64(i+1) * df
+	}
+	return f
+}
+
+// FFTShift shifts the zero-frequency bin of x to the center, in-place.
+// This cyclically rotates x left by ceil(len(x)/2), matching numpy.fft.fftshift
+// for both even and odd lengths. x may be of any length.
+func FFTShift(x []complex128) {
+	rotateLeft(x, (len(x)+1)/2)
+}
+
+// IFFTShift is the inverse of FFTShift, moving the center bin back to index 0, in-place.
+// This cyclically rotates x left by floor(len(x)/2), matching numpy.fft.ifftshift
+// for both even and odd lengths. x may be of any length.
+func IFFTShift(x []complex128) {
+	rotateLeft(x, len(x)/2)
+}
+
+// rotateLeft cyclically rotates x left by k in-place, using three reversals.
+func rotateLeft(x []complex128, k int) {
+	reverse(x[:k])
+	reverse(x[k:])
+	reverse(x)
+}
+
+// reverse reverses x in-place.
+func reverse(x []complex128) {
+	for i, j := 0, len(x)-1; i < j; i, j = i+1, j-1 {
+		x[i], x[j] = x[j], x[i]
+	}
+}
+
+// Conjugate replaces each entry in x with its complex conjugate, negating its
+// imaginary part, in-place.
+func Conjugate(x []complex128) {
+	for i, v := range x {
+		x[i] = complex(real(v), -imag(v))
+	}
+}
+
+// Conjugate64 replaces each entry in x with its complex conjugate, negating its
+// imaginary part, in-place.
+func Conjugate64(x []complex64) {
+	for i, v := range x {
+		x[i] = complex(real(v), -imag(v))
+	}
+}
+
+// HasInvalid returns true if any entry in x has a NaN or infinite real or imaginary part.
+func HasInvalid(x []complex128) bool {
+	return firstInvalid(x) >= 0
+}
+
+// firstInvalid returns the index of the first NaN or infinite entry in x, or -1 if there are none.
+func firstInvalid(x []complex128) int {
+	for i, v := range x {
+		if cmplx.IsNaN(v) || cmplx.IsInf(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isReal returns true if every entry in x has an imaginary part of exactly 0.
+func isReal(x []complex128) bool {
+	for _, v := range x {
+		if imag(v) != 0 {
+			return false
+		}
+	}
+	return true
+}
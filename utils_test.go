@@ -2,6 +2,7 @@ package fft
 
 import (
 	"math"
+	"math/cmplx"
 	"math/rand"
 	"testing"
 )
@@ -59,6 +60,33 @@ func TestNextPow2(t *testing.T) {
 	}
 }
 
+func TestLog2(t *testing.T) {
+	// Test all powers of 2 up to 2^62
+	for i := 0; i < 63; i++ {
+		x := 1 << uint64(i)
+		k, ok := Log2(x)
+		if !ok || k != i {
+			t.Errorf("Log2(%d), got: %d, %t, expected: %d, true", x, k, ok, i)
+		}
+		// Test NextPow2Exp(x) == i, and NextPow2Exp(x+1) == i+1
+		if r := NextPow2Exp(x); r != i {
+			t.Errorf("NextPow2Exp(%d), got: %d, expected: %d", x, r, i)
+		}
+		if r := NextPow2Exp(x + 1); r != i+1 {
+			t.Errorf("NextPow2Exp(%d+1), got: %d, expected: %d", x, r, i+1)
+		}
+	}
+	// Test non-powers of 2
+	for _, x := range []int{0, 3, 5, 6, 7, 100, 1<<20 + 1} {
+		if k, ok := Log2(x); ok || k != 0 {
+			t.Errorf("Log2(%d), got: %d, %t, expected: 0, false", x, k, ok)
+		}
+	}
+	if r := NextPow2Exp(0); r != 0 {
+		t.Errorf("NextPow2Exp(0), got: %d, expected: 0", r)
+	}
+}
+
 func checkZeroPadding(t *testing.T, x1, x2 []complex128, N1, N2 int) {
 	if len(x1) != N1 {
 		t.Errorf("ZeroPad old array length, got: %d, expected: %d", len(x1), N1)
@@ -89,6 +117,27 @@ func TestZeroPad(t *testing.T) {
 	}
 }
 
+func TestZeroPadTo(t *testing.T) {
+	// Test ZeroPadTo to a shorter length returns InputSizeError stating both lengths
+	_, err := ZeroPadTo(complexRand(10), 5)
+	checkIsInputSizeError(t, "ZeroPadTo(complexRand(10), 5)", err)
+	expect := "Size of ZeroPadTo length must be at least the input length 10, is: 5"
+	if err != nil && err.Error() != expect {
+		t.Errorf("ZeroPadTo(complexRand(10), 5) error, got: %s, expected: %s", err.Error(), expect)
+	}
+	for i := 0; i < 100; i++ {
+		// Test random lengths between 0 and 10000, and random paddings between 0 and 1000
+		N1 := rand.Intn(10000)
+		N2 := N1 + rand.Intn(1000)
+		x1 := complexRand(N1)
+		x2, err := ZeroPadTo(x1, N2)
+		if err != nil {
+			t.Errorf("ZeroPadTo error: %v", err)
+		}
+		checkZeroPadding(t, x1, x2, N1, N2)
+	}
+}
+
 func TestZeroPadToNextPow2(t *testing.T) {
 	// 0. Test n=0 returns [0]
 	r := ZeroPadToNextPow2(nil)
@@ -115,6 +164,69 @@ func TestZeroPadToNextPow2(t *testing.T) {
 	}
 }
 
+func TestPad(t *testing.T) {
+	x := []complex128{1, 2, 3}
+	for _, test := range []struct {
+		name   string
+		x      []complex128
+		N      int
+		mode   PadMode
+		expect []complex128
+	}{
+		{"Zero", x, 8, PadZero, []complex128{1, 2, 3, 0, 0, 0, 0, 0}},
+		{"Edge", x, 6, PadEdge, []complex128{1, 2, 3, 3, 3, 3}},
+		{"Reflect", x, 8, PadReflect, []complex128{1, 2, 3, 2, 1, 2, 3, 2}},
+		{"Reflect of 1 value", []complex128{4}, 3, PadReflect, []complex128{4, 4, 4}},
+		{"Edge of nothing", nil, 2, PadEdge, []complex128{0, 0}},
+		{"Reflect of nothing", nil, 2, PadReflect, []complex128{0, 0}},
+		{"Edge truncating", x, 2, PadEdge, []complex128{1, 2}},
+		{"of an unknown mode", x, 5, PadMode(9), []complex128{1, 2, 3, 0, 0}},
+	} {
+		got := Pad(test.x, test.N, test.mode)
+		if len(got) != len(test.expect) {
+			t.Errorf("Pad %s, got: %v, expected: %v", test.name, got, test.expect)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expect[i] {
+				t.Errorf("Pad %s, got: %v, expected: %v", test.name, got, test.expect)
+				break
+			}
+		}
+	}
+	// Test PadConstant fills with value, including when x is empty or truncated
+	for _, test := range []struct {
+		x      []complex128
+		N      int
+		value  complex128
+		expect []complex128
+	}{
+		{x, 6, 5i, []complex128{1, 2, 3, 5i, 5i, 5i}},
+		{nil, 2, 7, []complex128{7, 7}},
+		{x, 2, 7, []complex128{1, 2}},
+	} {
+		got := PadConstant(test.x, test.N, test.value)
+		if len(got) != len(test.expect) {
+			t.Errorf("PadConstant(%v, %d, %v), got: %v, expected: %v", test.x, test.N, test.value, got, test.expect)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expect[i] {
+				t.Errorf("PadConstant(%v, %d, %v), got: %v, expected: %v", test.x, test.N, test.value, got, test.expect)
+				break
+			}
+		}
+	}
+	// Test Pad with PadZero matches ZeroPad, and doesn't alter x
+	x1 := complexRand(100)
+	x2 := Pad(x1, 150, PadZero)
+	checkZeroPadding(t, x1, x2, 100, 150)
+	Pad(x, 8, PadReflect)
+	if x[0] != 1 || x[1] != 2 || x[2] != 3 {
+		t.Errorf("Pad altered its input: %v", x)
+	}
+}
+
 func TestFloat64ToComplex128Array(t *testing.T) {
 	// Test random arrays of length 0 to 1000
 	for i := 0; i < 1000; i++ {
@@ -150,6 +262,73 @@ func TestComplex128ToFloat64Array(t *testing.T) {
 	}
 }
 
+func TestInterleavedToComplex128(t *testing.T) {
+	// Test odd lengths return InputSizeError
+	_, err := InterleavedToComplex128(floatRand(7))
+	checkIsInputSizeError(t, "InterleavedToComplex128(floatRand(7))", err)
+	// Test random arrays of length 0 to 1000, and the round trip through Complex128ToInterleaved
+	for i := 0; i < 1000; i += 2 {
+		a := floatRand(i)
+		b, err := InterleavedToComplex128(a)
+		if err != nil {
+			t.Errorf("InterleavedToComplex128 error: %v", err)
+		}
+		if len(b) != i/2 {
+			t.Errorf("InterleavedToComplex128, got: len(b) = %v, expected: len(b) = %v", len(b), i/2)
+		}
+		for j := range b {
+			if real(b[j]) != a[2*j] || imag(b[j]) != a[2*j+1] {
+				t.Errorf("InterleavedToComplex128, got: b[j] = %v, expected: b[j] = %v", b[j], complex(a[2*j], a[2*j+1]))
+			}
+		}
+		c := Complex128ToInterleaved(b)
+		if len(c) != i {
+			t.Errorf("Complex128ToInterleaved, got: len(c) = %v, expected: len(c) = %v", len(c), i)
+		}
+		for j := range c {
+			if c[j] != a[j] {
+				t.Errorf("Complex128ToInterleaved, got: c[j] = %v, expected: c[j] = %v", c[j], a[j])
+			}
+		}
+	}
+}
+
+func TestDeinterleave(t *testing.T) {
+	// Test Interleave(Deinterleave(x)) == x for lengths 0 to 100
+	for n := 0; n <= 100; n++ {
+		x := complexRand(n)
+		even, odd := Deinterleave(x)
+		if len(even) != (n+1)/2 || len(odd) != n/2 {
+			t.Errorf("Deinterleave lengths, got: %d %d, expected: %d %d", len(even), len(odd), (n+1)/2, n/2)
+		}
+		for i := range x {
+			v := odd
+			if i%2 == 0 {
+				v = even
+			}
+			if v[i/2] != x[i] {
+				t.Errorf("Deinterleave differs, n=%d i=%d", n, i)
+			}
+		}
+		y := Interleave(even, odd)
+		if len(y) != n {
+			t.Errorf("Interleave length, got: %d, expected: %d", len(y), n)
+		}
+		for i := range y {
+			if y[i] != x[i] {
+				t.Errorf("Interleave(Deinterleave(x)) differs, n=%d i=%d got: %v, expected: %v", n, i, y[i], x[i])
+			}
+		}
+	}
+	// Test mismatched lengths panic
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Interleave(complexRand(1), complexRand(3)) did not panic")
+		}
+	}()
+	Interleave(complexRand(1), complexRand(3))
+}
+
 func TestRoundFloat64Array(t *testing.T) {
 	// Test random arrays of length 0 to 1000
 	for i := 0; i < 1000; i++ {
@@ -164,3 +343,151 @@ func TestRoundFloat64Array(t *testing.T) {
 		}
 	}
 }
+
+func TestCleanFloat64Array(t *testing.T) {
+	x := []float64{1e-13, -1e-13, 3 + 1e-12, -2 - 1e-11, 0.5, 2.25, 7 - 1e-6}
+	expect := []float64{0, 0, 3, -2, 0.5, 2.25, 7 - 1e-6}
+	CleanFloat64Array(x, 1e-9)
+	for i := range x {
+		if x[i] != expect[i] || math.Signbit(x[i]) != math.Signbit(expect[i]) {
+			t.Errorf("CleanFloat64Array, got: x[%d] = %v, expected: %v", i, x[i], expect[i])
+		}
+	}
+	// Test integer-valued convolutions are cleaned back to exact integers
+	a, b := []float64{1, 2, 3, 4, 5}, []float64{-1, 0, 7}
+	y, err := ConvolveReal(a, b)
+	if err != nil {
+		t.Errorf("ConvolveReal error: %v", err)
+	}
+	CleanFloat64Array(y, 1e-9)
+	for i, v := range y {
+		if v != math.Round(v) {
+			t.Errorf("CleanFloat64Array of integer convolution, got: y[%d] = %v, expected an integer", i, v)
+		}
+	}
+}
+
+func TestFFTFreq(t *testing.T) {
+	// Test odd and non-positive lengths return nil
+	for _, n := range []int{-2, 0, 1, 7} {
+		if f := FFTFreq(n, 1.0); f != nil {
+			t.Errorf("FFTFreq(%d, 1.0), got: %v, expected: nil", n, f)
+		}
+	}
+	// Test against numpy.fft.fftfreq(8, 1/16.0)
+	expect := []float64{0, 2, 4, 6, -8, -6, -4, -2}
+	got := FFTFreq(8, 16.0)
+	if len(got) != len(expect) {
+		t.Fatalf("FFTFreq(8, 16.0), got: len %d, expected: len %d", len(got), len(expect))
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("FFTFreq(8, 16.0), got: f[%d] = %v, expected: f[%d] = %v", i, got[i], i, expect[i])
+		}
+	}
+	// Test the bins match the DFT frequency k*sampleRate/n modulo sampleRate
+	for n := 2; n < (1 << 11); n <<= 1 {
+		f := FFTFreq(n, 1000.0)
+		for k := 0; k < n; k++ {
+			d := math.Mod(f[k]-float64(k)*1000.0/float64(n), 1000.0)
+			if math.Abs(d) > 1e-9 && math.Abs(math.Abs(d)-1000.0) > 1e-9 {
+				t.Errorf("FFTFreq(%d, 1000.0), got: f[%d] = %v, expected alias of %v", n, k, f[k], float64(k)*1000.0/float64(n))
+			}
+		}
+	}
+}
+
+func TestFFTShift(t *testing.T) {
+	// Test against numpy.fft.fftshift and numpy.fft.ifftshift
+	tests := []struct {
+		x, shift, ishift []complex128
+	}{
+		{nil, nil, nil},
+		{[]complex128{0}, []complex128{0}, []complex128{0}},
+		{[]complex128{0, 1, 2, 3}, []complex128{2, 3, 0, 1}, []complex128{2, 3, 0, 1}},
+		{[]complex128{0, 1, 2, 3, 4}, []complex128{3, 4, 0, 1, 2}, []complex128{2, 3, 4, 0, 1}},
+	}
+	for _, test := range tests {
+		x := copyVector(test.x)
+		FFTShift(x)
+		for i := range x {
+			if x[i] != test.shift[i] {
+				t.Errorf("FFTShift(%v), got: %v, expected: %v", test.x, x, test.shift)
+				break
+			}
+		}
+		x = copyVector(test.x)
+		IFFTShift(x)
+		for i := range x {
+			if x[i] != test.ishift[i] {
+				t.Errorf("IFFTShift(%v), got: %v, expected: %v", test.x, x, test.ishift)
+				break
+			}
+		}
+	}
+	// Test IFFTShift(FFTShift(x)) == x for all lengths up to 100
+	for n := 0; n < 100; n++ {
+		x := complexRand(n)
+		y := copyVector(x)
+		FFTShift(y)
+		IFFTShift(y)
+		for i := range x {
+			if x[i] != y[i] {
+				t.Errorf("IFFTShift(FFTShift(x)), n=%d, got: y[%d] = %v, expected: y[%d] = %v", n, i, y[i], i, x[i])
+			}
+		}
+	}
+}
+
+func TestConjugate(t *testing.T) {
+	x := []complex128{0, 1, 2i, complex(-3, 4), complex(5, -6)}
+	expect := []complex128{0, 1, -2i, complex(-3, -4), complex(5, 6)}
+	Conjugate(x)
+	for i := range x {
+		if x[i] != expect[i] {
+			t.Errorf("Conjugate, got: %v, expected: %v", x, expect)
+			break
+		}
+	}
+	x64 := []complex64{0, 1, 2i, complex(-3, 4), complex(5, -6)}
+	expect64 := []complex64{0, 1, -2i, complex(-3, -4), complex(5, 6)}
+	Conjugate64(x64)
+	for i := range x64 {
+		if x64[i] != expect64[i] {
+			t.Errorf("Conjugate64, got: %v, expected: %v", x64, expect64)
+			break
+		}
+	}
+	// Test IFFT(x) == conj(FFT(conj(x)))/N
+	y := complexRand(64)
+	z := copyVector(y)
+	IFFT(y)
+	Conjugate(z)
+	FFT(z)
+	Conjugate(z)
+	for i := range z {
+		if e := cmplx.Abs(z[i]/64 - y[i]); e > 1e-9 {
+			t.Errorf("inverse by conjugation differs from IFFT: i=%d got=%v expected=%v diff=%v", i, z[i]/64, y[i], e)
+		}
+	}
+}
+
+func TestHasInvalid(t *testing.T) {
+	if HasInvalid(nil) {
+		t.Errorf("HasInvalid(nil), got: true, expected: false")
+	}
+	x := complexRand(100)
+	if HasInvalid(x) {
+		t.Errorf("HasInvalid(complexRand(100)), got: true, expected: false")
+	}
+	for _, v := range []complex128{complex(math.NaN(), 0), complex(0, math.NaN()), complex(math.Inf(1), 0), complex(0, math.Inf(-1))} {
+		y := copyVector(x)
+		y[37] = v
+		if !HasInvalid(y) {
+			t.Errorf("HasInvalid with %v, got: false, expected: true", v)
+		}
+		if i := firstInvalid(y); i != 37 {
+			t.Errorf("firstInvalid with %v, got: %d, expected: 37", v, i)
+		}
+	}
+}
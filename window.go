@@ -5,6 +5,7 @@ import (
 	"math/cmplx"
 )
 
+// Window selects a window function to apply to the input data before a transform.
 type Window int
 
 const (
@@ -12,54 +13,205 @@ const (
 	Hanning
 	Hamming
 	Blackman
+	// Bartlett is the triangular window, with zeros at both ends.
+	Bartlett
+	// Kaiser takes the shape parameter beta, trading main-lobe width against side-lobe level.
+	// ApplyWindow uses a beta of 8.6, similar to Blackman.
+	Kaiser
+	// Gaussian takes the standard deviation sigma as a fraction of half the window length.
+	// ApplyWindow uses a sigma of 0.4. A sigma <= 0 is taken as the limit as sigma
+	// goes to 0: 1 at the center of the window, if there is a center sample, and 0 elsewhere.
+	Gaussian
+	// Tukey is the tapered cosine window, taking the fraction of the window inside the cosine tapers.
+	// A fraction of 0 is Rectangular, and 1 is Hanning. ApplyWindow uses a fraction of 0.5.
+	Tukey
+	// BlackmanHarris is the 4-term Blackman-Harris window, with side lobes below -92 dB.
+	BlackmanHarris
+	// FlatTop is the 5-term flat top window, whose flat main lobe measures the
+	// amplitude of a sinusoid accurately wherever it falls between bins.
+	FlatTop
 )
 
+// Periodic may be combined with any window, as in Hanning|Periodic, to select the
+// periodic form of the window (dividing by n rather than n-1), which is standard for
+// spectral analysis and STFTs. Without it windows use the symmetric form, which is
+// standard for filter design.
+const Periodic Window = 1 << 16
+
+// defaultWindowParam returns the parameter used by ApplyWindow for each parameterized window.
+func defaultWindowParam(window Window) float64 {
+	switch window &^ Periodic {
+	case Kaiser:
+		return 8.6
+	case Gaussian:
+		return 0.4
+	case Tukey:
+		return 0.5
+	}
+	return 0
+}
+
 // ApplyWindow applies the specified window function to the input data
 func ApplyWindow(x []complex128, window Window) []complex128 {
+	return ApplyWindowParam(x, window, defaultWindowParam(window))
+}
+
+// ApplyWindow64 applies the specified window function to the input data
+func ApplyWindow64(x []complex64, window Window) []complex64 {
+	return ApplyWindowParam64(x, window, defaultWindowParam(window))
+}
+
+// ApplyWindowParam applies the specified window function to the input data, using param
+// as the beta of Kaiser, the sigma of Gaussian, or the taper fraction of Tukey.
+// param is ignored by the other windows.
+func ApplyWindowParam(x []complex128, window Window, param float64) []complex128 {
 	n := len(x)
 
 	for i := 0; i < n; i++ {
-		var w float64
-		switch window {
-		case Rectangular:
-			w = 1.0
-		case Hanning:
-			w = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(n-1)))
-		case Hamming:
-			w = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(n-1))
-		case Blackman:
-			w = 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1)) +
-				0.08*math.Cos(4*math.Pi*float64(i)/float64(n-1))
-		}
+		w := windowValue(window, i, n, param)
 		x[i] = complex(real(x[i])*w, imag(x[i])*w)
 	}
 
 	return x
 }
 
-// ApplyWindow64 applies the specified window function to the input data
-func ApplyWindow64(x []complex64, window Window) []complex64 {
+// ApplyWindowParam64 applies the specified window function to the input data, using param
+// as the beta of Kaiser, the sigma of Gaussian, or the taper fraction of Tukey.
+// param is ignored by the other windows.
+func ApplyWindowParam64(x []complex64, window Window, param float64) []complex64 {
 	n := len(x)
 
 	for i := 0; i < n; i++ {
-		var w float64
-		switch window {
-		case Rectangular:
-			w = 1.0
-		case Hanning:
-			w = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(n-1)))
-		case Hamming:
-			w = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(n-1))
-		case Blackman:
-			w = 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1)) +
-				0.08*math.Cos(4*math.Pi*float64(i)/float64(n-1))
-		}
+		w := windowValue(window, i, n, param)
 		x[i] = complex(real(x[i])*float32(w), imag(x[i])*float32(w))
 	}
 
 	return x
 }
 
+// TaperEdges multiplies the first and last taperFraction of x by the cosine
+// tapers of the symmetric Tukey window, in-place, leaving the middle of x
+// untouched. This is ApplyWindowParam(x, Tukey, 2*taperFraction), without
+// visiting the middle, and reduces the ringing from the discontinuities at
+// the edges of a finite block, as when convolving it.
+// taperFraction is clamped to [0, 0.5], so 0 leaves x unchanged, and 0.5 applies Hanning.
+func TaperEdges(x []complex128, taperFraction float64) {
+	n := len(x)
+	if n < 2 || !(taperFraction > 0) {
+		return
+	}
+	param := 2 * min(taperFraction, 0.5)
+	m := float64(n - 1)
+	for i, j := 0, n-1; i <= j && float64(i)/m < param/2; i, j = i+1, j-1 {
+		w := windowValue(Tukey, i, n, param)
+		x[i] = complex(real(x[i])*w, imag(x[i])*w)
+		if j != i {
+			x[j] = complex(real(x[j])*w, imag(x[j])*w)
+		}
+	}
+}
+
+// windowWeights returns the values of the specified window function for a length n window,
+// using the same parameter as ApplyWindow.
+func windowWeights(window Window, n int) []float64 {
+	w := make([]float64, n)
+	param := defaultWindowParam(window)
+	for i := range w {
+		w[i] = windowValue(window, i, n, param)
+	}
+	return w
+}
+
+// windowValue computes the value of the specified window function at index i of n
+func windowValue(window Window, i, n int, param float64) float64 {
+	// A length 1 window is always 1, and would otherwise divide by zero
+	if n == 1 {
+		return 1.0
+	}
+	m := float64(n - 1)
+	if window&Periodic != 0 {
+		m = float64(n)
+	}
+	var w float64
+	switch window &^ Periodic {
+	case Rectangular:
+		w = 1.0
+	case Hanning:
+		w = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/m))
+	case Hamming:
+		w = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/m)
+	case Blackman:
+		w = 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/m) +
+			0.08*math.Cos(4*math.Pi*float64(i)/m)
+	case Bartlett:
+		w = 1 - math.Abs(2*float64(i)/m-1)
+	case Kaiser:
+		r := 2*float64(i)/m - 1
+		w = besselI0(param*math.Sqrt(1-r*r)) / besselI0(param)
+	case Gaussian:
+		r := 2*float64(i)/m - 1
+		if param > 0 {
+			w = math.Exp(-0.5 * (r / param) * (r / param))
+		} else if r == 0 {
+			// The limit as sigma goes to 0 is 1 at the center, and 0 elsewhere
+			w = 1.0
+		}
+	case BlackmanHarris:
+		w = cosineSum(float64(i)/m, 0.35875, 0.48829, 0.14128, 0.01168)
+	case FlatTop:
+		w = cosineSum(float64(i)/m, 0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+	case Tukey:
+		r := float64(i) / m
+		if r > 0.5 {
+			r = 1 - r
+		}
+		if r < param/2 {
+			w = 0.5 * (1 - math.Cos(2*math.Pi*r/param))
+		} else {
+			w = 1.0
+		}
+	}
+	return w
+}
+
+// cosineSum computes the generalized cosine window a0 - a1*cos(2*Pi*r) + a2*cos(4*Pi*r) - ...
+// at the position r in [0, 1] along the window
+func cosineSum(r float64, a ...float64) float64 {
+	w, sign := 0.0, 1.0
+	for k, ak := range a {
+		w += sign * ak * math.Cos(2*math.Pi*float64(k)*r)
+		sign = -sign
+	}
+	return w
+}
+
+// WindowAmplitudeCorrection returns the coherent gain of the specified window of
+// length n, sum(w)/n: the factor by which the window scales the amplitude of a
+// sinusoid in a spectrum. Divide measured peak amplitudes by it to recover the
+// true amplitudes. Returns 0 if n is not positive.
+func WindowAmplitudeCorrection(window Window, n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	s := 0.0
+	for _, w := range windowWeights(window, n) {
+		s += w
+	}
+	return s / float64(n)
+}
+
+// besselI0 computes the modified Bessel function of the first kind of order 0,
+// used by the Kaiser window, by summing its power series.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	q := x * x / 4
+	for k := 1.0; term > 1e-17*sum; k++ {
+		term *= q / (k * k)
+		sum += term
+	}
+	return sum
+}
+
 // PowerSpectrumPrecision computes the power spectrum of the FFT result
 func PowerSpectrumPrecision(x []complex128) []float64 {
 	n := len(x)
@@ -72,6 +224,29 @@ func PowerSpectrumPrecision(x []complex128) []float64 {
 	return result
 }
 
+// OneSidedPower computes the one-sided power spectrum of x, the FFT of a real signal,
+// returning the len(x)/2+1 bins from DC up to and including Nyquist, |X[k]|^2,
+// with the bins other than DC and Nyquist doubled to fold in the power of their
+// negative frequency mirrors. The sum of the result is the sum of
+// PowerSpectrumPrecision(x).
+// Assumes x is the FFT of a real signal, so that |X[N-k]| == |X[k]|, otherwise
+// the power of the negative frequencies is not what is folded in.
+// Returns nil if x is empty.
+func OneSidedPower(x []complex128) []float64 {
+	N := len(x)
+	if N == 0 {
+		return nil
+	}
+	y := make([]float64, N/2+1)
+	for k := range y {
+		y[k] = real(x[k])*real(x[k]) + imag(x[k])*imag(x[k])
+		if k != 0 && 2*k != N {
+			y[k] *= 2
+		}
+	}
+	return y
+}
+
 // PowerSpectrum computes the power spectrum of the FFT result
 func PowerSpectrum(x []complex64) []float32 {
 	n := len(x)
@@ -83,3 +258,17 @@ func PowerSpectrum(x []complex64) []float32 {
 
 	return result
 }
+
+// PowerSpectrumDB computes the power spectrum of the FFT result in decibels relative
+// to the full-scale amplitude fullScale, 10*log10(|x[i]|^2/fullScale^2), in a new array,
+// such as for displaying dBFS.
+// Values below floorDB (including the -Inf from a 0 magnitude) are clamped to floorDB.
+func PowerSpectrumDB(x []complex64, fullScale, floorDB float32) []float32 {
+	result := make([]float32, len(x))
+	ref := float64(fullScale) * float64(fullScale)
+	for i, v := range x {
+		p := float64(real(v))*float64(real(v)) + float64(imag(v))*float64(imag(v))
+		result[i] = float32(toDB(10*math.Log10(p/ref), float64(floorDB)))
+	}
+	return result
+}
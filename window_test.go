@@ -0,0 +1,235 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// windowOf returns the window function values for a length n window
+func windowOf(window Window, n int, param float64) []float64 {
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = 1
+	}
+	return Complex128ToFloat64Array(ApplyWindowParam(x, window, param))
+}
+
+func sum(x []float64) float64 {
+	s := 0.0
+	for _, v := range x {
+		s += v
+	}
+	return s
+}
+
+func TestWindowReference(t *testing.T) {
+	// Test against numpy.kaiser(12, 14)
+	expect := []float64{7.72686684e-06, 3.46009194e-03, 4.65200189e-02, 2.29737120e-01, 5.99885316e-01, 9.45674898e-01,
+		9.45674898e-01, 5.99885316e-01, 2.29737120e-01, 4.65200189e-02, 3.46009194e-03, 7.72686684e-06}
+	got := windowOf(Kaiser, 12, 14)
+	for i := range expect {
+		if e := math.Abs(got[i] - expect[i]); e > 1e-9 {
+			t.Errorf("Kaiser(12, 14), got: w[%d] = %v, expected: w[%d] = %v, diff=%v", i, got[i], i, expect[i], e)
+		}
+	}
+	// Test window sums against reference values
+	for _, test := range []struct {
+		name   string
+		window Window
+		n      int
+		param  float64
+		sum    float64
+	}{
+		{"Bartlett", Bartlett, 12, 0, 60.0 / 11.0},
+		{"Bartlett", Bartlett, 11, 0, 5.0},
+		{"Kaiser", Kaiser, 12, 14, sum(expect)},
+		{"Kaiser", Kaiser, 12, 0, 12},
+		{"Tukey", Tukey, 11, 0.5, 7.5},
+		{"Tukey", Tukey, 11, 0, 11},
+		{"Tukey", Tukey, 11, 1, sum(windowOf(Hanning, 11, 0))},
+		{"Gaussian", Gaussian, 3, 0.5, 1 + 2*math.Exp(-2)},
+		{"Gaussian", Gaussian, 11, 0, 1},
+		{"Gaussian", Gaussian, 11, -1, 1},
+		{"Gaussian", Gaussian, 12, 0, 0},
+		{"Gaussian|Periodic", Gaussian | Periodic, 12, 0, 1},
+	} {
+		if got := sum(windowOf(test.window, test.n, test.param)); math.Abs(got-test.sum) > 1e-9 {
+			t.Errorf("sum(%s(%d, %v)), got: %v, expected: %v", test.name, test.n, test.param, got, test.sum)
+		}
+	}
+}
+
+func TestWindowAmplitudeCorrection(t *testing.T) {
+	// Test periodic cosine-sum windows have a coherent gain of exactly a0
+	for _, test := range []struct {
+		name   string
+		window Window
+		gain   float64
+	}{
+		{"Rectangular", Rectangular | Periodic, 1},
+		{"Hanning", Hanning | Periodic, 0.5},
+		{"BlackmanHarris", BlackmanHarris | Periodic, 0.35875},
+		{"FlatTop", FlatTop | Periodic, 0.21557895},
+	} {
+		if got := WindowAmplitudeCorrection(test.window, 64); math.Abs(got-test.gain) > 1e-9 {
+			t.Errorf("WindowAmplitudeCorrection(%s, 64), got: %v, expected: %v", test.name, got, test.gain)
+		}
+	}
+	if got := WindowAmplitudeCorrection(Hanning, 0); got != 0 {
+		t.Errorf("WindowAmplitudeCorrection(Hanning, 0), got: %v, expected: 0", got)
+	}
+	// Test FlatTop recovers the amplitude of a sinusoid between bins to within 0.01 dB
+	N, amplitude := 256, 3.0
+	for _, bin := range []float64{10, 10.25, 10.5} {
+		x := make([]complex128, N)
+		for i := range x {
+			x[i] = complex(amplitude*math.Cos(2*math.Pi*bin*float64(i)/float64(N)), 0)
+		}
+		ApplyWindow(x, FlatTop|Periodic)
+		fft(x)
+		peak := 0.0
+		for _, v := range Magnitude(x[:N/2]) {
+			peak = math.Max(peak, v)
+		}
+		got := 2 * peak / (float64(N) * WindowAmplitudeCorrection(FlatTop|Periodic, N))
+		if e := math.Abs(20 * math.Log10(got/amplitude)); e > 0.01 {
+			t.Errorf("FlatTop amplitude at bin %v, got: %v, expected: %v, error=%v dB", bin, got, amplitude, e)
+		}
+	}
+}
+
+func TestApplyWindow64(t *testing.T) {
+	// Test ApplyWindow64 matches ApplyWindow for every window
+	for window := Rectangular; window <= FlatTop; window++ {
+		x := complexRand(33)
+		y := Complex128ToComplex64(x)
+		ApplyWindow(x, window)
+		ApplyWindow64(y, window)
+		for i := range x {
+			if e := math.Abs(real(x[i]) - float64(real(y[i]))); e > 1e-5 {
+				t.Errorf("ApplyWindow and ApplyWindow64 differ: window=%d x[%d]=%v y[%d]=%v", window, i, x[i], i, y[i])
+			}
+		}
+	}
+}
+
+func TestWindowLengthOne(t *testing.T) {
+	// Test length 1 windows are 1, rather than NaN
+	for window := Rectangular; window <= FlatTop; window++ {
+		for _, w := range []Window{window, window | Periodic} {
+			x := ApplyWindow([]complex128{2}, w)
+			if x[0] != 2 {
+				t.Errorf("ApplyWindow([2], %d), got: %v, expected: 2", w, x[0])
+			}
+			y := ApplyWindow64([]complex64{2}, w)
+			if y[0] != 2 {
+				t.Errorf("ApplyWindow64([2], %d), got: %v, expected: 2", w, y[0])
+			}
+		}
+	}
+}
+
+func TestPeriodicWindow(t *testing.T) {
+	// Test a periodic window of length n is the first n values of the symmetric window of length n+1
+	for window := Rectangular; window <= FlatTop; window++ {
+		for n := 2; n < 64; n++ {
+			p := windowOf(window|Periodic, n, defaultWindowParam(window))
+			s := windowOf(window, n+1, defaultWindowParam(window))
+			for i := range p {
+				if e := math.Abs(p[i] - s[i]); e > 1e-12 {
+					t.Errorf("Periodic window %d differs: n=%d p[%d]=%v s[%d]=%v", window, n, i, p[i], i, s[i])
+				}
+			}
+		}
+	}
+	// Test a periodic Hanning window sums to exactly n/2, so overlapping at 50% is constant
+	for n := 2; n < 64; n += 2 {
+		if got := sum(windowOf(Hanning|Periodic, n, 0)); math.Abs(got-float64(n)/2) > 1e-9 {
+			t.Errorf("sum(Hanning|Periodic(%d)), got: %v, expected: %v", n, got, float64(n)/2)
+		}
+	}
+}
+
+func TestTaperEdges(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 7, 64, 101} {
+		for _, f := range []float64{-1, 0, 0.05, 0.1, 0.25, 0.5, 2, math.NaN()} {
+			x := complexRand(n)
+			y := copyVector(x)
+			TaperEdges(y, f)
+			// Test TaperEdges matches the Tukey window with twice the fraction, clamped
+			var w []float64
+			if f > 0 {
+				w = windowOf(Tukey, n, 2*min(f, 0.5))
+			}
+			for i := range x {
+				expect := x[i]
+				if w != nil {
+					expect *= complex(w[i], 0)
+				}
+				if e := cmplx.Abs(y[i] - expect); e > 1e-12 {
+					t.Errorf("TaperEdges(n=%d, %v) differs: y[%d]=%v, expected=%v, diff=%v", n, f, i, y[i], expect, e)
+				}
+				// Test the middle is left exactly untouched
+				if r := float64(i) / float64(n-1); r >= f && r <= 1-f && y[i] != x[i] {
+					t.Errorf("TaperEdges(n=%d, %v) altered the middle: y[%d]=%v, x[%d]=%v", n, f, i, y[i], i, x[i])
+				}
+			}
+		}
+	}
+}
+
+func TestOneSidedPower(t *testing.T) {
+	if OneSidedPower(nil) != nil {
+		t.Errorf("OneSidedPower(nil), expected nil")
+	}
+	// Test the DC and Nyquist bins are unscaled, and the others doubled
+	got := OneSidedPower([]complex128{1, 2i, complex(3, 4), 2i})
+	expect := []float64{1, 8, 25}
+	for k := range expect {
+		if got[k] != expect[k] {
+			t.Errorf("OneSidedPower, got: %v, expected: %v", got, expect)
+			break
+		}
+	}
+	// Test odd lengths, which have no Nyquist bin
+	got = OneSidedPower([]complex128{1, 2, 3, 3, 2})
+	expect = []float64{1, 8, 18}
+	for k := range expect {
+		if got[k] != expect[k] {
+			t.Errorf("OneSidedPower of odd length, got: %v, expected: %v", got, expect)
+			break
+		}
+	}
+	// Test the one-sided power of a real signal has the same total as the two-sided power
+	for N := 1; N < (1 << 10); N <<= 1 {
+		x := Float64ToComplex128Array(floatRand(N))
+		fft(x)
+		one, two := sum(OneSidedPower(x)), sum(PowerSpectrumPrecision(x))
+		if len(OneSidedPower(x)) != N/2+1 || math.Abs(one-two) > 1e-9*two {
+			t.Errorf("OneSidedPower total differs: N=%d got=%v expected=%v", N, one, two)
+		}
+	}
+}
+
+func TestPowerSpectrumDB(t *testing.T) {
+	x := []complex64{0, 1, 10i, complex(-3, 4), 1e-20}
+	expect := []float32{-120, 0, 20, float32(20 * math.Log10(5)), -120}
+	got := PowerSpectrumDB(x, 1, -120)
+	for i := range expect {
+		if math.Abs(float64(got[i]-expect[i])) > 1e-4 {
+			t.Errorf("PowerSpectrumDB(%v, 1, -120), got: db[%d] = %v, expected: db[%d] = %v", x[i], i, got[i], i, expect[i])
+		}
+	}
+	// Test a full-scale sinusoid's bin is at 0dBFS, matching PowerSpectrum in dB
+	got = PowerSpectrumDB([]complex64{512, 51.2}, 512, -200)
+	if math.Abs(float64(got[0])) > 1e-4 || math.Abs(float64(got[1]+20)) > 1e-4 {
+		t.Errorf("PowerSpectrumDB([512, 51.2], 512, -200), got: %v, expected: [0, -20]", got)
+	}
+	p := PowerSpectrum(x[1:4])
+	for i, v := range PowerSpectrumDB(x[1:4], 1, -120) {
+		if e := math.Abs(float64(v) - 10*math.Log10(float64(p[i]))); e > 1e-4 {
+			t.Errorf("PowerSpectrumDB and PowerSpectrum differ: i=%d got=%v expected=%v", i, v, 10*math.Log10(float64(p[i])))
+		}
+	}
+}